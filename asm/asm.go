@@ -0,0 +1,259 @@
+package asm
+
+import (
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"io"
+	"maps"
+	"strconv"
+)
+
+// maxConstant is the largest value that fits in the 15 bits available to A-instructions
+const maxConstant = 0b0111_1111_1111_1111
+
+var (
+	computations = map[string]int{
+		"0":   0b0101010,
+		"1":   0b0111111,
+		"-1":  0b0111010,
+		"D":   0b0001100,
+		"A":   0b0110000,
+		"M":   0b1110000,
+		"!D":  0b0001101,
+		"!A":  0b0110001,
+		"!M":  0b1110001,
+		"-D":  0b0001111,
+		"-A":  0b0110011,
+		"-M":  0b1110011,
+		"D+1": 0b0011111,
+		"A+1": 0b0110111,
+		"M+1": 0b1110111,
+		"D-1": 0b0001110,
+		"A-1": 0b0110010,
+		"M-1": 0b1110010,
+		"D+A": 0b0000010,
+		"D+M": 0b1000010,
+		"D-A": 0b0010011,
+		"D-M": 0b1010011,
+		"A-D": 0b0000111,
+		"M-D": 0b1000111,
+		"D&A": 0b0000000,
+		"D&M": 0b1000000,
+		"D|A": 0b0010101,
+		"D|M": 0b1010101,
+	}
+	jumps = map[string]int{
+		"JGT": 0b001,
+		"JEQ": 0b010,
+		"JGE": 0b011,
+		"JLT": 0b100,
+		"JNE": 0b101,
+		"JLE": 0b110,
+		"JMP": 0b111,
+	}
+	destinations = map[uint8]int{
+		'M': 0b001,
+		'D': 0b010,
+		'A': 0b100,
+	}
+)
+
+// Options configures the assembler. The zero value assembles programs for the Hack platform as described by the book.
+type Options struct {
+	// Devices predefines the symbols of the registers of the devices attached by the simulator, see device.Symbols.
+	// Programs written for the plain Hack platform are free to use the same names for their own variables and labels,
+	// which is why the symbols are only predefined on request.
+	Devices bool
+}
+
+// Assemble reads Hack assembly source code from r and translates it into Hack machine code, one 16-bit word per
+// instruction.
+func (o Options) Assemble(r io.Reader) ([]uint16, error) {
+	program, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return assemble(program, o.symbols())
+}
+
+// AssembleMapped works like Assemble but also returns the SourceMap of the program, which was read from the source file
+// called file.
+func (o Options) AssembleMapped(file string, r io.Reader) ([]uint16, *SourceMap, error) {
+	program, err := Parse(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	bin, err := assemble(program, o.symbols())
+	if err != nil {
+		return nil, nil, err
+	}
+	return bin, NewSourceMap(file, program), nil
+}
+
+// symbols returns the symbols which are bound to an address before the program is assembled
+func (o Options) symbols() map[string]int {
+	if !o.Devices {
+		return predefined
+	}
+	symbols := maps.Clone(predefined)
+	for name, addr := range device.Symbols {
+		symbols[name] = int(addr)
+	}
+	return symbols
+}
+
+// Assemble assembles the Hack assembly source code read from r with the zero Options.
+func Assemble(r io.Reader) ([]uint16, error) {
+	return Options{}.Assemble(r)
+}
+
+// AssembleSignals works like Assemble but returns the machine code in the Signal representation used by the chip
+// package, which makes the result suitable for loading straight into a chip.ROM.
+func AssembleSignals(r io.Reader) ([][16]chip.Signal, error) {
+	words, err := Assemble(r)
+	if err != nil {
+		return nil, err
+	}
+	return chip.NewROM(words), nil
+}
+
+// AssembleMapped assembles the Hack assembly source code read from r with the zero Options, see
+// Options.AssembleMapped.
+func AssembleMapped(file string, r io.Reader) ([]uint16, *SourceMap, error) {
+	return Options{}.AssembleMapped(file, r)
+}
+
+func assemble(program []Instruction, symbols map[string]int) ([]uint16, error) {
+	mem, err := buildMemoryMap(program, symbols)
+	if err != nil {
+		return nil, err
+	}
+	bin := make([]uint16, 0, len(program))
+	for _, ins := range program {
+		switch v := ins.(type) {
+		case Load:
+			w, err := assembleLoadInstruction(mem, v)
+			if err != nil {
+				return nil, err
+			}
+			bin = append(bin, w)
+		case Compute:
+			w, err := assembleComputeInstruction(v)
+			if err != nil {
+				return nil, err
+			}
+			bin = append(bin, w)
+		default:
+		}
+		if len(bin) > chip.ROMSize {
+			return nil, fmt.Errorf("%v: program exceeds the ROM capacity of %d instructions", ins.Pos(), chip.ROMSize)
+		}
+	}
+	return bin, nil
+}
+
+func assembleLoadInstruction(mem map[string]int, v Load) (uint16, error) {
+	if !v.Constant() {
+		return uint16(mem[v.Value] & 0b0111_1111_1111_1111), nil
+	}
+	bin, err := strconv.Atoi(v.Value)
+	if err != nil || bin > maxConstant {
+		return 0, fmt.Errorf("%v: constant %s exceeds the largest A-instruction value of %d", v.Position, v.Value, maxConstant)
+	}
+	return uint16(bin), nil
+}
+
+func assembleComputeInstruction(v Compute) (uint16, error) {
+	bin, ok := computations[v.Comp]
+	if !ok {
+		return 0, fmt.Errorf("%v: unexpected computational segment %s", v.Position, v.Comp)
+	}
+	bin = bin << 6
+	if v.Dest != "" {
+		dest := 0
+		for i := range v.Dest {
+			d, ok := destinations[v.Dest[i]]
+			if !ok {
+				return 0, fmt.Errorf("%v: invalid destination %s", v.Position, v.Dest)
+			}
+			dest = dest | d
+		}
+		bin = bin | (dest << 3)
+	}
+	if v.Jump != "" {
+		jump, ok := jumps[v.Jump]
+		if !ok {
+			return 0, fmt.Errorf("%v: invalid jump %s", v.Position, v.Jump)
+		}
+		bin = bin | jump
+	}
+	bin = bin | 0b1110_0000_0000_0000
+	return uint16(bin), nil
+}
+
+// predefined holds the symbols which are bound to an address before the program is assembled
+var predefined = map[string]int{
+	"R0":     0,
+	"R1":     1,
+	"R2":     2,
+	"R3":     3,
+	"R4":     4,
+	"R5":     5,
+	"R6":     6,
+	"R7":     7,
+	"R8":     8,
+	"R9":     9,
+	"R10":    10,
+	"R11":    11,
+	"R12":    12,
+	"R13":    13,
+	"R14":    14,
+	"R15":    15,
+	"SP":     0,
+	"LCL":    1,
+	"ARG":    2,
+	"THIS":   3,
+	"THAT":   4,
+	"SCREEN": chip.ScreenAddress,
+	"KBD":    chip.KeyboardAddress,
+}
+
+// buildMemoryMap assigns an address to every symbol used by the program, starting out from symbols. Labels are bound to
+// ROM addresses while variables are allocated in RAM from address 16 and up to, but not including, the memory mapped
+// screen.
+func buildMemoryMap(program []Instruction, symbols map[string]int) (map[string]int, error) {
+	mem := maps.Clone(symbols)
+	for _, ins := range program {
+		if label, ok := ins.(Label); ok {
+			if _, ok := symbols[label.Name]; ok {
+				// The label would be resolved to the address of the symbol, leaving it unreachable
+				return nil, fmt.Errorf("%v: label %s collides with a predefined symbol", label.Position, label.Name)
+			}
+		}
+	}
+	for name, addr := range Labels(program) {
+		if _, ok := mem[name]; !ok {
+			mem[name] = int(addr)
+		}
+	}
+	cursor := 16
+	for _, ins := range program {
+		switch v := ins.(type) {
+		case Load:
+			if v.Constant() {
+				continue
+			}
+			_, ok := mem[v.Value]
+			if !ok {
+				if cursor >= mem["SCREEN"] {
+					return nil, fmt.Errorf("%v: variable %s does not fit in RAM below the screen", v.Position, v.Value)
+				}
+				mem[v.Value] = cursor
+				cursor++
+			}
+		default:
+		}
+	}
+	return mem, nil
+}
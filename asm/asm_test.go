@@ -0,0 +1,261 @@
+package asm
+
+import (
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"strings"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	var assertions = []struct {
+		name string
+		src  string
+		bin  []uint16
+	}{
+		{
+			name: "constants and computations",
+			src:  "@20\nD=A\n@60\nD=D+A\n@5\nM=D\n",
+			bin: []uint16{
+				0b0000_0000_0001_0100,
+				0b1110_1100_0001_0000,
+				0b0000_0000_0011_1100,
+				0b1110_0000_1001_0000,
+				0b0000_0000_0000_0101,
+				0b1110_0011_0000_1000,
+			},
+		},
+		{
+			name: "labels and variables",
+			src:  "(LOOP)\n@i\nM=!M\n@LOOP\n0;JMP",
+			bin: []uint16{
+				0b0000_0000_0001_0000,
+				0b1111_1100_0100_1000,
+				0b0000_0000_0000_0000,
+				0b1110_1010_1000_0111,
+			},
+		},
+		{
+			name: "predefined symbols",
+			src:  "@SCREEN\n@KBD\n@R15\n@THAT",
+			bin:  []uint16{16_384, 24_576, 15, 4},
+		},
+		{
+			name: "device registers are ordinary variables by default",
+			src:  "@RANDOM\nD=M\n@TIMER_TICKS\nM=D\n@RANDOM",
+			bin: []uint16{
+				0b0000_0000_0001_0000,
+				0b1111_1100_0001_0000,
+				0b0000_0000_0001_0001,
+				0b1110_0011_0000_1000,
+				0b0000_0000_0001_0000,
+			},
+		},
+	}
+	for _, a := range assertions {
+		t.Run(a.name, func(t *testing.T) {
+			bin, err := Assemble(strings.NewReader(a.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(bin) != len(a.bin) {
+				t.Fatalf("expected %d words but got %d", len(a.bin), len(bin))
+			}
+			for i := range bin {
+				if bin[i] != a.bin[i] {
+					t.Errorf("expected %016b at address %d but got %016b", a.bin[i], i, bin[i])
+				}
+			}
+			signals, err := AssembleSignals(strings.NewReader(a.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i := range signals {
+				if w := chip.Wrap(&signals[i]).Uint16(); w != a.bin[i] {
+					t.Errorf("expected %016b at address %d but got %016b", a.bin[i], i, w)
+				}
+			}
+		})
+	}
+}
+
+// TestAssemble_devices checks that the assembler agrees with the addresses of the devices
+func TestAssemble_devices(t *testing.T) {
+	for symbol, addr := range device.Symbols {
+		t.Run(symbol, func(t *testing.T) {
+			bin, err := Options{Devices: true}.Assemble(strings.NewReader("@" + symbol))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bin[0] != addr {
+				t.Errorf("expected %d but got %d", addr, bin[0])
+			}
+		})
+	}
+}
+
+func TestAssemble_errors(t *testing.T) {
+	variables := func(n int) string {
+		var sb strings.Builder
+		for i := range n {
+			fmt.Fprintf(&sb, "@var%d\n", i)
+		}
+		return sb.String()
+	}
+	var assertions = []struct {
+		name    string
+		options Options
+		src     string
+		err     string
+	}{
+		{
+			name: "program exceeds ROM capacity",
+			src:  generate(32768) + "D=A\n",
+			err:  "exceeds the ROM capacity",
+		},
+		{
+			name: "constant exceeds 15 bits",
+			src:  "@32768\n",
+			err:  "1:1: constant 32768 exceeds",
+		},
+		{
+			name: "variables overflow into the screen",
+			src:  variables(16384 - 16 + 1),
+			err:  "variable var16368 does not fit",
+		},
+		{
+			name: "label collides with a register",
+			src:  "@0\n(R1)\n@R1\n0;JMP",
+			err:  "2:1: label R1 collides with a predefined symbol",
+		},
+		{
+			name:    "label collides with a device",
+			options: Options{Devices: true},
+			src:     "(RANDOM)\n@RANDOM\n0;JMP",
+			err:     "1:1: label RANDOM collides with a predefined symbol",
+		},
+	}
+	for _, a := range assertions {
+		t.Run(a.name, func(t *testing.T) {
+			_, err := a.options.Assemble(strings.NewReader(a.src))
+			if err == nil || !strings.Contains(err.Error(), a.err) {
+				t.Errorf("expected error containing %q but got %v", a.err, err)
+			}
+		})
+	}
+	t.Run("program filling the entire ROM", func(t *testing.T) {
+		bin, err := Assemble(strings.NewReader(generate(32768)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(bin) != 32768 {
+			t.Errorf("expected 32768 instructions but got %d", len(bin))
+		}
+	})
+}
+
+// generate produces Hack assembly source code consisting of n instructions that exercise labels, variables, constants,
+// comments and all parts of C-instructions.
+func generate(n int) string {
+	var sb strings.Builder
+	comps := []string{"0", "1", "-1", "D", "A", "!M", "D+1", "M-1", "D+A", "D-M", "A-D", "D&A", "D|M"}
+	dests := []string{"", "M", "D", "MD", "A", "AM", "AD", "AMD"}
+	jumps := []string{"", "JGT", "JEQ", "JGE", "JLT", "JNE", "JLE", "JMP"}
+	for i := range n {
+		if i%64 == 0 {
+			fmt.Fprintf(&sb, "(L%d) // label %d\n", i, i)
+		}
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&sb, "@v%d\n", i%100)
+		case 1:
+			fmt.Fprintf(&sb, "  @%d\n", i)
+		case 2:
+			fmt.Fprintf(&sb, "@L%d\n", i/64*64)
+		default:
+			dest, jump := dests[i%len(dests)], jumps[i/4%len(jumps)]
+			if dest != "" {
+				sb.WriteString(dest + "=")
+			}
+			sb.WriteString(comps[i%len(comps)])
+			if jump != "" {
+				sb.WriteString(";" + jump)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// baselineSource generates the source code which BenchmarkAssemble assembles, avoiding '!' which the baseline cannot lex
+func baselineSource() string {
+	return strings.ReplaceAll(generate(32768), "!M", "-M")
+}
+
+// TestAssemble_baseline checks that the baseline which BenchmarkAssemble measures against produces the same machine
+// code as the current assembler
+func TestAssemble_baseline(t *testing.T) {
+	src := baselineSource()
+	bin, err := Assemble(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	baseline, err := baselineAssemble(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(baseline) != len(bin) {
+		t.Fatalf("expected %d words but got %d", len(bin), len(baseline))
+	}
+	for i := range bin {
+		if w := chip.Wrap(&baseline[i]).Uint16(); w != bin[i] {
+			t.Fatalf("expected %016b at address %d but got %016b", bin[i], i, w)
+		}
+	}
+}
+
+func BenchmarkAssemble(b *testing.B) {
+	src := baselineSource()
+	var assertions = []struct {
+		name     string
+		assemble func(src string) error
+	}{
+		{
+			name: "single pass",
+			assemble: func(src string) error {
+				_, err := Assemble(strings.NewReader(src))
+				return err
+			},
+		},
+		{
+			name: "baseline",
+			assemble: func(src string) error {
+				_, err := baselineAssemble(src)
+				return err
+			},
+		},
+	}
+	for _, assert := range assertions {
+		b.Run(assert.name, func(b *testing.B) {
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			for range b.N {
+				if err := assert.assemble(src); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	src := generate(32768)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := Parse(strings.NewReader(src)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
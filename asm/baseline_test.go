@@ -0,0 +1,438 @@
+package asm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"strconv"
+	"unicode"
+)
+
+// This file holds a copy of the assembler as it was before the pipeline was made single-pass, which BenchmarkAssemble
+// measures the current assembler against. The code is kept as it was apart from prefixing its names with baseline to
+// avoid clashing with the current code, and it is not meant to be maintained beyond that. Note that it cannot lex '!',
+// which was only added to the lexer later on.
+
+var baselineSymbols = map[uint8]variant{
+	'\n': linefeed,
+	'@':  at,
+	'-':  minus,
+	'+':  plus,
+	'&':  and,
+	'|':  or,
+	';':  semicolon,
+	'(':  lparen,
+	')':  rparen,
+	'=':  equals,
+}
+
+type baselineLexer struct {
+	src    string
+	cursor int
+}
+
+func (l *baselineLexer) more() bool {
+	return l.cursor < len(l.src)
+}
+
+func (l *baselineLexer) peek() (token, error) {
+	prev := l.cursor
+	defer func() {
+		l.cursor = prev
+	}()
+	return l.next()
+}
+
+func (l *baselineLexer) next() (token, error) {
+	l.literal(l.space)
+	if l.cursor >= len(l.src) {
+		return token{
+			variant: eof,
+		}, nil
+	}
+	char := l.src[l.cursor]
+	if symbol, ok := baselineSymbols[char]; ok {
+		l.cursor++
+		return token{
+			variant: symbol,
+			literal: string(char),
+		}, nil
+	}
+	if char == '/' {
+		return l.comment()
+	}
+	if numerical(char) {
+		return token{
+			variant: integer,
+			literal: l.literal(numerical),
+		}, nil
+	}
+	literal := l.literal(l.identifier)
+	keyword, ok := keywords[literal]
+	if ok {
+		return token{
+			variant: keyword,
+			literal: literal,
+		}, nil
+	}
+	return token{
+		variant: identifier,
+		literal: literal,
+	}, nil
+}
+
+func (l *baselineLexer) seek(c uint8) error {
+	for ; l.cursor < len(l.src) && l.src[l.cursor] != c; l.cursor++ {
+	}
+	if l.cursor == len(l.src) {
+		return fmt.Errorf("character '%s' not found", string(c))
+	}
+	return nil
+}
+
+func (l *baselineLexer) space(c uint8) bool {
+	return c != '\n' && unicode.IsSpace(rune(c))
+}
+
+func (l *baselineLexer) literal(fn func(uint8) bool) string {
+	literal := ""
+	for ; l.cursor < len(l.src) && fn(l.src[l.cursor]); l.cursor++ {
+		literal += string(l.src[l.cursor])
+	}
+	return literal
+}
+
+func (l *baselineLexer) comment() (token, error) {
+	if l.src[l.cursor+1] != '/' {
+		return token{}, errors.New("invalid token '/'")
+	}
+	l.cursor += 2
+	if err := l.seek('\n'); err != nil {
+		return token{}, err
+	}
+	l.cursor += 1
+	return l.next()
+}
+
+func (l *baselineLexer) identifier(c uint8) bool {
+	if unicode.IsSpace(rune(c)) {
+		return false
+	}
+	if baselineAlphanumerical(c) {
+		return true
+	}
+	switch c {
+	case '_', '.', '$', ':':
+		return true
+	default:
+		return false
+	}
+}
+
+func baselineAlphanumerical(c uint8) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || numerical(c)
+}
+
+type baselineLoad struct {
+	value token
+}
+
+type baselineCompute struct {
+	dest *token
+	comp string
+	jump *token
+}
+
+type baselineLabel struct {
+	value token
+}
+
+type baselineParser struct {
+	lexer baselineLexer
+}
+
+func (p *baselineParser) more() bool {
+	return p.lexer.more()
+}
+
+func (p *baselineParser) next() (any, error) {
+	if err := p.seek(p.clear); err != nil {
+		return nil, err
+	}
+	tok, err := p.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.variant {
+	case eof:
+		return nil, nil
+	case at:
+		return p.a()
+	case lparen:
+		return p.label()
+	default:
+		return p.c()
+	}
+}
+
+func (p *baselineParser) a() (baselineLoad, error) {
+	if _, err := p.want(at); err != nil {
+		return baselineLoad{}, err
+	}
+	tok, err := p.lexer.next()
+	if err != nil {
+		return baselineLoad{}, err
+	}
+	if tok.variant != integer && tok.variant != identifier {
+		return baselineLoad{}, fmt.Errorf("unexpected token for A-instruction '%v'", tok)
+	}
+	if err := p.seek(p.clear); err != nil {
+		return baselineLoad{}, err
+	}
+	return baselineLoad{value: tok}, nil
+}
+
+func (p *baselineParser) label() (baselineLabel, error) {
+	if _, err := p.want(lparen); err != nil {
+		return baselineLabel{}, err
+	}
+	name, err := p.want(identifier)
+	if err != nil {
+		return baselineLabel{}, err
+	}
+	if _, err := p.want(rparen); err != nil {
+		return baselineLabel{}, err
+	}
+	if err := p.seek(p.clear); err != nil {
+		return baselineLabel{}, err
+	}
+	return baselineLabel{value: name}, nil
+}
+
+func (p *baselineParser) c() (comp baselineCompute, err error) {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return baselineCompute{}, err
+	}
+	next, err := p.lexer.peek()
+	if err != nil {
+		return baselineCompute{}, err
+	}
+	if next.variant == equals {
+		_, _ = p.want(equals)
+		comp.dest = &token{
+			variant: tok.variant,
+			literal: tok.literal,
+		}
+		tok, err = p.lexer.next()
+		if err != nil {
+			return baselineCompute{}, err
+		}
+	}
+	for tok.variant != semicolon && tok.variant != linefeed {
+		comp.comp += tok.literal
+		tok, err = p.lexer.next()
+		if err != nil {
+			return baselineCompute{}, err
+		}
+	}
+	if tok.variant == semicolon {
+		jmp, err := p.lexer.next()
+		if err != nil {
+			return baselineCompute{}, err
+		}
+		comp.jump = &token{
+			variant: jmp.variant,
+			literal: jmp.literal,
+		}
+		if err := p.seek(p.clear); err != nil {
+			return baselineCompute{}, err
+		}
+	}
+	return comp, nil
+}
+
+func (p *baselineParser) seek(fn func(*token) bool) error {
+	tok, err := p.lexer.peek()
+	if err != nil {
+		return err
+	}
+	for fn(&tok) && tok.variant != eof {
+		_, err = p.lexer.next()
+		if err != nil {
+			return err
+		}
+		tok, err = p.lexer.peek()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *baselineParser) clear(tok *token) bool {
+	return tok.variant == linefeed
+}
+
+func (p *baselineParser) want(v variant) (token, error) {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return token{}, err
+	}
+	if tok.variant != v {
+		return token{}, fmt.Errorf("expected '%v' token but found '%v'", v, tok.variant)
+	}
+	return tok, nil
+}
+
+func baselineAssemble(src string) ([][16]chip.Signal, error) {
+	mem, err := baselineBuildMemoryMap(src)
+	if err != nil {
+		return nil, err
+	}
+	var program [][16]chip.Signal
+	ps := baselineParser{
+		lexer: baselineLexer{
+			src: src,
+		},
+	}
+	for ps.more() {
+		ins, err := ps.next()
+		if err != nil {
+			return nil, err
+		}
+		switch v := ins.(type) {
+		case baselineLoad:
+			bin, err := baselineAssembleLoadInstruction(mem, v)
+			if err != nil {
+				return nil, err
+			}
+			program = append(program, bin)
+		case baselineCompute:
+			bin, err := baselineAssembleComputeInstruction(v)
+			if err != nil {
+				return nil, err
+			}
+			program = append(program, bin)
+		default:
+		}
+	}
+	return program, nil
+}
+
+func baselineAssembleLoadInstruction(mem map[string]int, v baselineLoad) ([16]chip.Signal, error) {
+	var bin int
+	var err error
+	if v.value.variant == integer {
+		bin, err = strconv.Atoi(v.value.literal)
+	} else if v.value.variant == identifier {
+		bin = mem[v.value.literal]
+	} else {
+		return [16]chip.Signal{}, fmt.Errorf("unexpected load token %+v", v.value)
+	}
+	if err != nil {
+		return [16]chip.Signal{}, err
+	}
+	bin = bin & 0b0111_1111_1111_1111
+	return chip.WrapUint16(uint16(bin)).Copy(), nil
+}
+
+func baselineAssembleComputeInstruction(v baselineCompute) ([16]chip.Signal, error) {
+	bin, ok := computations[v.comp]
+	if !ok {
+		return [16]chip.Signal{}, fmt.Errorf("unexpected computational segment %s", v.comp)
+	}
+	bin = bin << 6
+	if v.dest != nil {
+		dest := 0
+		for i := range v.dest.literal {
+			d, ok := destinations[v.dest.literal[i]]
+			if !ok {
+				return [16]chip.Signal{}, fmt.Errorf("invalid destination %+v", v.dest)
+			}
+			dest = dest | d
+		}
+		bin = bin | (dest << 3)
+	}
+	if v.jump != nil {
+		jump, ok := jumps[v.jump.literal]
+		if !ok {
+			return [16]chip.Signal{}, fmt.Errorf("invalid jump %+v", v.jump)
+		}
+		bin = bin | jump
+	}
+	bin = bin | 0b1110_0000_0000_0000
+	return chip.WrapUint16(uint16(bin)).Copy(), nil
+}
+
+func baselineBuildMemoryMap(src string) (map[string]int, error) {
+	mem := map[string]int{
+		"R0":     0,
+		"R1":     1,
+		"R2":     2,
+		"R3":     3,
+		"R4":     4,
+		"R5":     5,
+		"R6":     6,
+		"R7":     7,
+		"R8":     8,
+		"R9":     9,
+		"R10":    10,
+		"R11":    11,
+		"R12":    12,
+		"R13":    13,
+		"R14":    14,
+		"R15":    15,
+		"SP":     0,
+		"LCL":    1,
+		"ARG":    2,
+		"THIS":   3,
+		"THAT":   4,
+		"SCREEN": 16_384,
+		"KBD":    24_576,
+	}
+	ps := baselineParser{
+		lexer: baselineLexer{
+			src: src,
+		},
+	}
+	for line := 0; ps.more(); line++ {
+		ins, err := ps.next()
+		if err != nil {
+			return nil, err
+		}
+		switch v := ins.(type) {
+		case baselineLabel:
+			if _, ok := mem[v.value.literal]; !ok {
+				mem[v.value.literal] = line
+			}
+			line--
+		default:
+		}
+	}
+	ps = baselineParser{
+		lexer: baselineLexer{
+			src: src,
+		},
+	}
+	cursor := 16
+	for ps.more() {
+		ins, err := ps.next()
+		if err != nil {
+			return nil, err
+		}
+		switch v := ins.(type) {
+		case baselineLoad:
+			if v.value.variant != identifier {
+				continue
+			}
+			_, ok := mem[v.value.literal]
+			if !ok {
+				mem[v.value.literal] = cursor
+				cursor++
+			}
+		default:
+		}
+	}
+	return mem, nil
+}
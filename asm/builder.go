@@ -0,0 +1,137 @@
+package asm
+
+import (
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"strconv"
+)
+
+// Builder offers a programmatic alternative to writing Hack assembly source code. Each instruction added to the
+// builder is checked against the same tables that the assembler uses, which means that misspelled mnemonics are caught
+// as soon as the program is built rather than by a misbehaving Computer. The zero value is an empty program ready for
+// use.
+//
+//	var b asm.Builder
+//	b.A("10").C("D", "A", "")
+//	b.At("LOOP")
+//	b.C("D", "D-1", "")
+//	b.A("LOOP").C("", "D", "JGT")
+//	rom, err := b.ROM()
+//
+// Only the first error encountered is retained and every call made after it is ignored.
+type Builder struct {
+	// Devices predefines the symbols of the device registers, see Options.Devices. It must be set before the first
+	// instruction is added.
+	Devices bool
+
+	program []Instruction
+	labels  map[string]bool
+	err     error
+}
+
+// At binds label to the address of the next instruction added to the builder. The label must not be one of the
+// predefined symbols, such as R1 or SCREEN, nor a device register when Devices is set.
+func (b *Builder) At(label string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if !symbol(label) {
+		return b.fail(fmt.Errorf("invalid label %q", label))
+	}
+	if _, ok := b.options().symbols()[label]; ok {
+		// The assembler resolves the symbol to its predefined address, which would leave the label unreachable
+		return b.fail(fmt.Errorf("label %q collides with a predefined symbol", label))
+	}
+	if b.labels == nil {
+		b.labels = make(map[string]bool)
+	}
+	if b.labels[label] {
+		return b.fail(fmt.Errorf("label %q declared more than once", label))
+	}
+	b.labels[label] = true
+	b.program = append(b.program, Label{Name: label})
+	return b
+}
+
+// A adds an A-instruction to the program. The value is either a decimal constant in the range 0 to 32767 or a symbol,
+// where symbols that are neither predefined nor declared as labels are allocated as variables.
+func (b *Builder) A(value string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	ins := Load{Value: value}
+	if ins.Constant() {
+		n, err := strconv.Atoi(value)
+		if err != nil || n > maxConstant {
+			return b.fail(fmt.Errorf("invalid constant %q", value))
+		}
+	} else if !symbol(value) {
+		return b.fail(fmt.Errorf("invalid symbol %q", value))
+	}
+	b.program = append(b.program, ins)
+	return b
+}
+
+// C adds a C-instruction to the program. Both dest and jump may be left empty to omit them.
+func (b *Builder) C(dest, comp, jump string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	ins := Compute{Dest: dest, Comp: comp, Jump: jump}
+	if _, err := assembleComputeInstruction(ins); err != nil {
+		return b.fail(err)
+	}
+	b.program = append(b.program, ins)
+	return b
+}
+
+// Program returns the instructions that have been added to the builder so far, or the first error encountered while
+// adding them.
+func (b *Builder) Program() ([]Instruction, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.program, nil
+}
+
+// Assemble resolves all symbols used by the program and translates it into Hack machine code.
+func (b *Builder) Assemble() ([]uint16, error) {
+	program, err := b.Program()
+	if err != nil {
+		return nil, err
+	}
+	return assemble(program, b.options().symbols())
+}
+
+// ROM assembles the program and returns it as a ROM that can be loaded into a chip.Computer.
+func (b *Builder) ROM() (chip.ROM, error) {
+	words, err := b.Assemble()
+	if err != nil {
+		return nil, err
+	}
+	return chip.NewROM(words), nil
+}
+
+func (b *Builder) options() Options {
+	return Options{Devices: b.Devices}
+}
+
+func (b *Builder) fail(err error) *Builder {
+	b.err = fmt.Errorf("instruction %d: %w", len(b.program), err)
+	return b
+}
+
+// symbol returns true if s would be lexed as a single identifier by the lexer.
+func symbol(s string) bool {
+	if s == "" || numerical(s[0]) {
+		return false
+	}
+	var l lexer
+	for _, r := range s {
+		if !l.identifier(r) {
+			return false
+		}
+	}
+	_, keyword := keywords[s]
+	return !keyword
+}
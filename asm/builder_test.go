@@ -0,0 +1,142 @@
+package asm
+
+import (
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"testing"
+)
+
+func TestBuilder_ROM(t *testing.T) {
+	var assertions = []struct {
+		name  string
+		build func(b *Builder)
+		ticks int
+		mem   map[uint16]uint16
+	}{
+		{
+			name: "add two integers and store in RAM",
+			build: func(b *Builder) {
+				b.A("20").C("D", "A", "")
+				b.A("60").C("D", "D+A", "")
+				b.A("5").C("M", "D", "")
+			},
+			ticks: 6,
+			mem: map[uint16]uint16{
+				5: 80,
+			},
+		},
+		{
+			name: "multiply an integer with itself",
+			build: func(b *Builder) {
+				b.A("R1").C("M", "0", "")
+				b.A("4").C("D", "A", "")
+				b.A("R0").C("M", "D", "")
+				b.At("LOOP")
+				b.A("R1").C("D", "M", "")
+				b.A("4").C("D", "D+A", "")
+				b.A("R1").C("M", "D", "")
+				b.A("R0").C("D", "M", "")
+				b.C("D", "D-1", "")
+				b.C("M", "D", "")
+				b.A("LOOP").C("", "D", "JGT")
+				b.At("END")
+				b.A("END").C("", "0", "JMP")
+			},
+			ticks: 200,
+			mem: map[uint16]uint16{
+				1: 16,
+			},
+		},
+	}
+	for _, a := range assertions {
+		t.Run(a.name, func(t *testing.T) {
+			var b Builder
+			a.build(&b)
+			rom, err := b.ROM()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := chip.NewComputer(rom)
+			for range a.ticks {
+				c.Tick(chip.Inactive)
+			}
+			for address, value := range a.mem {
+				out := c.RAM().Out(chip.Inactive, chip.WrapUint16(address).Address(), chip.NullWord)
+				if out.Uint16() != value {
+					t.Errorf("expected RAM[%v] to contain %v but got %v", address, value, out.Uint16())
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_errors(t *testing.T) {
+	var assertions = []struct {
+		name  string
+		build func(b *Builder)
+	}{
+		{
+			name: "unknown computation",
+			build: func(b *Builder) {
+				b.C("D", "D*A", "")
+			},
+		},
+		{
+			name: "unknown destination",
+			build: func(b *Builder) {
+				b.C("X", "D", "")
+			},
+		},
+		{
+			name: "unknown jump",
+			build: func(b *Builder) {
+				b.C("", "D", "JXX")
+			},
+		},
+		{
+			name: "constant out of range",
+			build: func(b *Builder) {
+				b.A("32768")
+			},
+		},
+		{
+			name: "invalid symbol",
+			build: func(b *Builder) {
+				b.A("LO OP")
+			},
+		},
+		{
+			name: "duplicate label",
+			build: func(b *Builder) {
+				b.At("LOOP").At("LOOP")
+			},
+		},
+		{
+			name: "label collides with a register",
+			build: func(b *Builder) {
+				b.At("R1")
+			},
+		},
+		{
+			name: "label collides with a device",
+			build: func(b *Builder) {
+				b.Devices = true
+				b.At("RANDOM")
+			},
+		},
+		{
+			name: "error is retained across calls",
+			build: func(b *Builder) {
+				b.C("", "D", "JXX").A("1").C("D", "A", "")
+			},
+		},
+	}
+	for _, a := range assertions {
+		t.Run(a.name, func(t *testing.T) {
+			var b Builder
+			a.build(&b)
+			if _, err := b.Assemble(); err == nil {
+				t.Errorf("expected error but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package asm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	symbols = map[uint8]variant{
+		'\n': linefeed,
+		'@':  at,
+		'-':  minus,
+		'+':  plus,
+		'&':  and,
+		'|':  or,
+		';':  semicolon,
+		'(':  lparen,
+		')':  rparen,
+		'=':  equals,
+		'!':  not,
+	}
+	// lookup mirrors symbols in a table indexed by character, which makes it considerably cheaper to consult than the map
+	// since it is done for every token. Characters that are not symbols map to eof.
+	lookup = func() (table [256]variant) {
+		for c, v := range symbols {
+			table[c] = v
+		}
+		return table
+	}()
+	keywords = map[string]variant{
+		"JGT": jgt,
+		"JEQ": jeq,
+		"JGE": jge,
+		"JLT": jlt,
+		"JNE": jne,
+		"JLE": jle,
+		"JMP": jmp,
+	}
+)
+
+const (
+	eof variant = iota
+	at
+	minus
+	plus
+	and
+	or
+	equals
+	identifier
+	integer
+	lparen
+	rparen
+	semicolon
+	jgt
+	jeq
+	jge
+	jlt
+	jne
+	jle
+	jmp
+	linefeed
+	not
+)
+
+type variant int
+
+type token struct {
+	variant variant
+	literal string
+	// pos is the byte offset of the first character of the token in the source code
+	pos int
+}
+
+type lexer struct {
+	src    string
+	cursor int
+	// peeked holds the token most recently returned by peek until it is consumed by next, which spares the lexer from
+	// processing the same characters twice. The cursor position after the peeked token is kept in end.
+	peeked   token
+	buffered bool
+	end      int
+	// lines holds the offsets at which each line of the source code begins, it is lazily built the first time a
+	// position is requested
+	lines []int
+	// line is the line number of the most recently requested position
+	line int
+}
+
+// more returns true if the lexer has not yet reached the end of the source code
+func (l *lexer) more() bool {
+	return l.cursor < len(l.src)
+}
+
+// peek returns the next token but does not allow the cursor to proceed past said token, which means that the effect of
+// calling peek several times in a row is that identical values are returned each time
+func (l *lexer) peek() (token, error) {
+	if l.buffered {
+		return l.peeked, nil
+	}
+	prev := l.cursor
+	tok, err := l.lex()
+	if err != nil {
+		l.cursor = prev
+		return token{}, err
+	}
+	l.peeked, l.buffered, l.end = tok, true, l.cursor
+	l.cursor = prev
+	return tok, nil
+}
+
+// next returns the next token that can be extracted from the underlying source code from the current cursor position
+// and places cursor at the next character after all characters belonging to the most recently processed token. Calling
+// next after the cursor has reached the end of the underlying source code is safe and will only result in an eof token
+// being returned together with a nil error.
+func (l *lexer) next() (token, error) {
+	if l.buffered {
+		l.buffered = false
+		l.cursor = l.end
+		return l.peeked, nil
+	}
+	return l.lex()
+}
+
+func (l *lexer) lex() (token, error) {
+	l.literal(l.space)
+	pos := l.cursor
+	if l.cursor >= len(l.src) {
+		return token{
+			variant: eof,
+			pos:     pos,
+		}, nil
+	}
+	char := l.src[l.cursor]
+	if char == '\r' {
+		// Both Windows (CRLF) and classic Mac OS (CR) line endings are regarded as a single linefeed
+		l.cursor++
+		if l.cursor < len(l.src) && l.src[l.cursor] == '\n' {
+			l.cursor++
+		}
+		return token{
+			variant: linefeed,
+			literal: l.src[pos:l.cursor],
+			pos:     pos,
+		}, nil
+	}
+	if symbol := lookup[char]; symbol != eof {
+		l.cursor++
+		return token{
+			variant: symbol,
+			literal: l.src[pos:l.cursor],
+			pos:     pos,
+		}, nil
+	}
+	if char == '/' {
+		return l.comment()
+	}
+	// Identifiers cannot start with a digit, therefore we must first check if the current character is an integer to
+	// decide whether to regard this as an integer Literal
+	if numerical(char) {
+		return token{
+			variant: integer,
+			literal: l.literal(l.digit),
+			pos:     pos,
+		}, nil
+	}
+	literal := l.literal(l.identifier)
+	if literal == "" {
+		r, size := utf8.DecodeRuneInString(l.src[pos:])
+		if r == utf8.RuneError && size == 1 {
+			return token{}, fmt.Errorf("%v: invalid UTF-8 encoding", l.position(pos))
+		}
+		return token{}, fmt.Errorf("%v: unexpected character %q", l.position(pos), r)
+	}
+	// All keywords are jump mnemonics of exactly three characters, anything else can skip the keyword lookup
+	if len(literal) == 3 {
+		if keyword, ok := keywords[literal]; ok {
+			return token{
+				variant: keyword,
+				literal: literal,
+				pos:     pos,
+			}, nil
+		}
+	}
+	return token{
+		variant: identifier,
+		literal: literal,
+		pos:     pos,
+	}, nil
+}
+
+// position translates a byte offset into the source code to a Pos
+func (l *lexer) position(offset int) Pos {
+	if l.lines == nil {
+		l.lines = []int{0}
+		for i := range len(l.src) {
+			if l.src[i] == '\n' || (l.src[i] == '\r' && (i+1 == len(l.src) || l.src[i+1] != '\n')) {
+				l.lines = append(l.lines, i+1)
+			}
+		}
+	}
+	// Positions are mostly requested in increasing order while parsing, so the line of the previous request is a good
+	// starting point that spares a binary search over all lines
+	line := l.line
+	if line == 0 || l.lines[line-1] > offset {
+		line = sort.Search(len(l.lines), func(i int) bool {
+			return l.lines[i] > offset
+		})
+	}
+	for line < len(l.lines) && l.lines[line] <= offset {
+		line++
+	}
+	l.line = line
+	return Pos{
+		Offset: offset,
+		Line:   line,
+		Column: offset - l.lines[line-1] + 1,
+	}
+}
+
+// space reports whether r is whitespace other than a line ending. The byte order mark is regarded as whitespace since
+// editors on Windows like to place it at the start of files.
+func (l *lexer) space(r rune) bool {
+	return r != '\n' && r != '\r' && (unicode.IsSpace(r) || r == '\uFEFF')
+}
+
+func (l *lexer) digit(r rune) bool {
+	return r < utf8.RuneSelf && numerical(uint8(r))
+}
+
+// literal advances the cursor for as long as fn holds for the current character and returns the characters it passed as
+// a slice of the source code. Characters outside the ASCII range are decoded as UTF-8 before being passed to fn, where
+// invalid encodings are passed as utf8.RuneError.
+func (l *lexer) literal(fn func(rune) bool) string {
+	start := l.cursor
+	for l.cursor < len(l.src) {
+		r, size := rune(l.src[l.cursor]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRuneInString(l.src[l.cursor:])
+		}
+		if !fn(r) {
+			break
+		}
+		l.cursor += size
+	}
+	return l.src[start:l.cursor]
+}
+
+// comment skips past a line comment or a block comment. Line comments run until the end of the line but leave the line
+// ending in place, since it terminates whatever instruction preceded the comment. Block comments are regarded as
+// whitespace unless they span several lines, in which case they are returned as a single linefeed.
+func (l *lexer) comment() (token, error) {
+	pos := l.cursor
+	if l.cursor+1 >= len(l.src) || (l.src[l.cursor+1] != '/' && l.src[l.cursor+1] != '*') {
+		return token{}, fmt.Errorf("%v: invalid token '/'", l.position(pos))
+	}
+	if l.src[l.cursor+1] == '/' {
+		for ; l.cursor < len(l.src) && l.src[l.cursor] != '\n' && l.src[l.cursor] != '\r'; l.cursor++ {
+		}
+		return l.lex()
+	}
+	end := strings.Index(l.src[l.cursor+2:], "*/")
+	if end == -1 {
+		return token{}, fmt.Errorf("%v: block comment is never terminated", l.position(pos))
+	}
+	l.cursor += 2 + end + 2
+	if strings.ContainsAny(l.src[pos:l.cursor], "\r\n") {
+		return token{
+			variant: linefeed,
+			literal: l.src[pos:l.cursor],
+			pos:     pos,
+		}, nil
+	}
+	return l.lex()
+}
+
+// identifier reports whether r may be part of a symbol. Besides the characters allowed by the Hack specification any
+// Unicode letter or digit is accepted, which lets programs use symbols written in languages other than English.
+func (l *lexer) identifier(r rune) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return true
+	}
+	switch r {
+	case '_', '.', '$', ':':
+		return true
+	default:
+		return false
+	}
+}
+
+func numerical(c uint8) bool {
+	return c >= '0' && c <= '9'
+}
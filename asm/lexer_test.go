@@ -0,0 +1,421 @@
+package asm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLexer_next(t *testing.T) {
+	var assertions = []struct {
+		src    string
+		tokens []token
+	}{
+		{
+			src: "@17\n",
+			tokens: []token{
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: integer,
+					literal: "17",
+				},
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+			},
+		},
+		{
+			src: "ADM=A+1\n",
+			tokens: []token{
+				{
+					variant: identifier,
+					literal: "ADM",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "A",
+				},
+				{
+					variant: plus,
+					literal: "+",
+				},
+				{
+					variant: integer,
+					literal: "1",
+				},
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+			},
+		},
+		{
+			src: "//this is a comment\n@2000",
+			tokens: []token{
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: integer,
+					literal: "2000",
+				},
+			},
+		},
+		{
+			src: "A;D;M",
+			tokens: []token{
+				{
+					variant: identifier,
+					literal: "A",
+				},
+				{
+					variant: semicolon,
+					literal: ";",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: semicolon,
+					literal: ";",
+				},
+				{
+					variant: identifier,
+					literal: "M",
+				},
+			},
+		},
+		{
+			src: "D;JGT",
+			tokens: []token{
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: semicolon,
+					literal: ";",
+				},
+				{
+					variant: jgt,
+					literal: "JGT",
+				},
+			},
+		},
+		{
+			src: "@10\nD=A+1\nM=D\nD;JNE",
+			tokens: []token{
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: integer,
+					literal: "10",
+				},
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "A",
+				},
+				{
+					variant: plus,
+					literal: "+",
+				},
+				{
+					variant: integer,
+					literal: "1",
+				},
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+				{
+					variant: identifier,
+					literal: "M",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: semicolon,
+					literal: ";",
+				},
+				{
+					variant: jne,
+					literal: "JNE",
+				},
+			},
+		},
+		{
+			src: "@1\r\nD=A\r",
+			tokens: []token{
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: integer,
+					literal: "1",
+				},
+				{
+					variant: linefeed,
+					literal: "\r\n",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "A",
+				},
+				{
+					variant: linefeed,
+					literal: "\r",
+				},
+			},
+		},
+		{
+			src: "D=A // trailing comment\n@1 // comment without newline",
+			tokens: []token{
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "A",
+				},
+				{
+					variant: linefeed,
+					literal: "\n",
+				},
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: integer,
+					literal: "1",
+				},
+			},
+		},
+		{
+			src: "@1 /* inline */ D=A /* spans\nlines */ M=D",
+			tokens: []token{
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: integer,
+					literal: "1",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "A",
+				},
+				{
+					variant: linefeed,
+					literal: "/* spans\nlines */",
+				},
+				{
+					variant: identifier,
+					literal: "M",
+				},
+				{
+					variant: equals,
+					literal: "=",
+				},
+				{
+					variant: identifier,
+					literal: "D",
+				},
+			},
+		},
+		{
+			src: "\uFEFF(SLÖJD)\u00A0@räknare",
+			tokens: []token{
+				{
+					variant: lparen,
+					literal: "(",
+				},
+				{
+					variant: identifier,
+					literal: "SLÖJD",
+				},
+				{
+					variant: rparen,
+					literal: ")",
+				},
+				{
+					variant: at,
+					literal: "@",
+				},
+				{
+					variant: identifier,
+					literal: "räknare",
+				},
+			},
+		},
+	}
+	for _, a := range assertions {
+		t.Run(fmt.Sprintf("given src %v", a.src), func(t *testing.T) {
+			l := lexer{src: a.src}
+			i := 0
+			var err error
+			var tok token
+			for tok, err = l.next(); tok.variant != eof && err == nil; {
+				if a.tokens[i].variant != tok.variant {
+					t.Errorf("expected tok variant %v but got %v", a.tokens[i].variant, tok.variant)
+				}
+				if a.tokens[i].literal != tok.literal {
+					t.Errorf("expected tok variant %v but got %v", a.tokens[i].variant, tok.variant)
+				}
+				tok, err = l.next()
+				i += 1
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func BenchmarkLexer_next(b *testing.B) {
+	src := generate(32768)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	for range b.N {
+		l := lexer{src: src}
+		for tok, err := l.next(); tok.variant != eof; tok, err = l.next() {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestLexer_next_errors(t *testing.T) {
+	var assertions = []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{
+			name: "lone slash",
+			src:  "@1\nD=D/A",
+			err:  "2:4: invalid token '/'",
+		},
+		{
+			name: "slash at end of source",
+			src:  "@1 /",
+			err:  "1:4: invalid token '/'",
+		},
+		{
+			name: "unterminated block comment",
+			src:  "@1\r\n/* never ends *",
+			err:  "2:1: block comment is never terminated",
+		},
+		{
+			name: "unexpected character",
+			src:  "@1\nD=D*A",
+			err:  "2:4: unexpected character '*'",
+		},
+		{
+			name: "invalid utf-8",
+			src:  "@a\xffb",
+			err:  "1:3: invalid UTF-8 encoding",
+		},
+	}
+	for _, a := range assertions {
+		t.Run(a.name, func(t *testing.T) {
+			l := lexer{src: a.src}
+			var err error
+			for tok := (token{variant: at}); tok.variant != eof && err == nil; {
+				tok, err = l.next()
+			}
+			if err == nil || err.Error() != a.err {
+				t.Errorf("expected error %q but got %v", a.err, err)
+			}
+		})
+	}
+}
+
+// FuzzLexer_next asserts that the lexer never panics and always makes progress, regardless of how malformed its input is.
+// The seed corpus lives in testdata/fuzz/FuzzLexer_next.
+func FuzzLexer_next(f *testing.F) {
+	f.Add("@17\nD=A\n")
+	f.Add("(LOOP)\r\n@LOOP\r\n0;JMP // done")
+	f.Add("/* block */ M=M+1 /* spanning\nlines */")
+	f.Fuzz(func(t *testing.T, src string) {
+		l := lexer{src: src}
+		for {
+			prev := l.cursor
+			tok, err := l.next()
+			if err != nil || tok.variant == eof {
+				return
+			}
+			if l.cursor <= prev {
+				t.Fatalf("lexer did not advance past offset %d on token %+v", prev, tok)
+			}
+			if tok.pos < prev || tok.pos+len(tok.literal) > l.cursor {
+				t.Fatalf("token %+v lies outside of the consumed range [%d, %d)", tok, prev, l.cursor)
+			}
+		}
+	})
+}
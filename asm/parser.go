@@ -0,0 +1,310 @@
+package asm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Pos describes a location in Hack assembly source code. Offset is the zero-based byte offset into the source while Line
+// and Column are both one-based, with Column counted in bytes.
+type Pos struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Instruction is a single node in the abstract syntax tree of a Hack assembly program. It is implemented by Load,
+// Compute and Label.
+type Instruction interface {
+	// Literal returns the Hack assembly source code representation of the instruction
+	Literal() string
+	// Pos returns the position of the first character of the instruction in the source code it was parsed from
+	Pos() Pos
+}
+
+// Load represents an A-instruction such as `@17` or `@LOOP`.
+type Load struct {
+	Position Pos
+	// Value is either a non-negative decimal constant or a symbol
+	Value string
+}
+
+func (l Load) Literal() string {
+	return fmt.Sprintf("@%s", l.Value)
+}
+
+func (l Load) Pos() Pos {
+	return l.Position
+}
+
+// Constant returns true if the value of the A-instruction is a decimal constant rather than a symbol. Symbols can never
+// begin with a digit, which makes the first character of the value sufficient to tell them apart.
+func (l Load) Constant() bool {
+	return len(l.Value) > 0 && numerical(l.Value[0])
+}
+
+// Compute represents a C-instruction such as `D=D+A;JGT`. Both Dest and Jump are optional and left empty when omitted
+// from the source code.
+type Compute struct {
+	Position Pos
+	Dest     string
+	Comp     string
+	Jump     string
+}
+
+func (c Compute) Literal() string {
+	var str string
+	if c.Dest != "" {
+		str += fmt.Sprintf("%s=", c.Dest)
+	}
+	str += c.Comp
+	if c.Jump != "" {
+		str += fmt.Sprintf(";%s", c.Jump)
+	}
+	return str
+}
+
+func (c Compute) Pos() Pos {
+	return c.Position
+}
+
+// Label represents a label pseudo-instruction such as `(LOOP)`. Labels do not produce any machine code but bind a
+// symbol to the address of the instruction that follows them.
+type Label struct {
+	Position Pos
+	Name     string
+}
+
+func (l Label) Literal() string {
+	return fmt.Sprintf("(%s)", l.Name)
+}
+
+func (l Label) Pos() Pos {
+	return l.Position
+}
+
+// Parse reads all Hack assembly source code from r and returns the instructions it contains in the order in which they
+// appear.
+func Parse(r io.Reader) ([]Instruction, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parse(string(src))
+}
+
+func parse(src string) ([]Instruction, error) {
+	// Most lines hold exactly one instruction, which makes the line count a cheap but decent estimate of the final size
+	program := make([]Instruction, 0, strings.Count(src, "\n")+1)
+	ps := parser{
+		lexer: lexer{
+			src: src,
+		},
+	}
+	for ps.more() {
+		ins, err := ps.next()
+		if err != nil {
+			return nil, err
+		}
+		if ins == nil {
+			break
+		}
+		program = append(program, ins)
+	}
+	return program, nil
+}
+
+// Fprint writes the Hack assembly source code representation of program to w, one instruction per line.
+func Fprint(w io.Writer, program []Instruction) error {
+	for _, ins := range program {
+		if _, err := fmt.Fprintln(w, ins.Literal()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type parser struct {
+	lexer lexer
+}
+
+func (p *parser) more() bool {
+	return p.lexer.more()
+}
+
+func (p *parser) next() (Instruction, error) {
+	if err := p.seek(p.clear); err != nil {
+		return nil, err
+	}
+	tok, err := p.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.variant {
+	case eof:
+		return nil, nil
+	case at:
+		return p.a()
+	case lparen:
+		return p.label()
+	default:
+		return p.c()
+	}
+}
+
+func (p *parser) a() (Load, error) {
+	start, err := p.want(at)
+	if err != nil {
+		return Load{}, err
+	}
+	tok, err := p.lexer.next()
+	if err != nil {
+		return Load{}, err
+	}
+	if tok.variant != integer && tok.variant != identifier {
+		return Load{}, fmt.Errorf("%v: unexpected token for A-instruction '%v'", p.lexer.position(tok.pos), tok.literal)
+	}
+	if err := p.terminate(); err != nil {
+		return Load{}, err
+	}
+	return Load{Position: p.lexer.position(start.pos), Value: tok.literal}, nil
+}
+
+func (p *parser) label() (Label, error) {
+	start, err := p.want(lparen)
+	if err != nil {
+		return Label{}, err
+	}
+	name, err := p.want(identifier)
+	if err != nil {
+		return Label{}, err
+	}
+	if _, err := p.want(rparen); err != nil {
+		return Label{}, err
+	}
+	if err := p.terminate(); err != nil {
+		return Label{}, err
+	}
+	return Label{Position: p.lexer.position(start.pos), Name: name.literal}, nil
+}
+
+func (p *parser) c() (comp Compute, err error) {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return Compute{}, err
+	}
+	comp.Position = p.lexer.position(tok.pos)
+	next, err := p.lexer.peek()
+	if err != nil {
+		return Compute{}, err
+	}
+	if next.variant == equals {
+		if tok.variant != identifier {
+			return Compute{}, fmt.Errorf("%v: unexpected destination '%v'", p.lexer.position(tok.pos), tok.literal)
+		}
+		_, _ = p.want(equals)
+		comp.Dest = tok.literal
+		// Fetch the next token for parsing the compute field
+		tok, err = p.lexer.next()
+		if err != nil {
+			return Compute{}, err
+		}
+	}
+	// The computation is sliced straight out of the source code unless it contains whitespace, in which case its tokens
+	// have to be joined together to form the canonical mnemonic
+	start, end := tok.pos, tok.pos
+	var spaced strings.Builder
+	for tok.variant != semicolon && tok.variant != linefeed && tok.variant != eof {
+		switch tok.variant {
+		case identifier, integer, minus, plus, and, or, not:
+		default:
+			return Compute{}, fmt.Errorf("%v: unexpected token in computation '%v'", p.lexer.position(tok.pos), tok.literal)
+		}
+		if tok.pos != end && spaced.Len() == 0 {
+			spaced.WriteString(p.lexer.src[start:end])
+		}
+		if spaced.Len() > 0 {
+			spaced.WriteString(tok.literal)
+		}
+		end = tok.pos + len(tok.literal)
+		tok, err = p.lexer.next()
+		if err != nil {
+			return Compute{}, err
+		}
+	}
+	comp.Comp = p.lexer.src[start:end]
+	if spaced.Len() > 0 {
+		comp.Comp = spaced.String()
+	}
+	if comp.Comp == "" {
+		return Compute{}, fmt.Errorf("%v: missing computation", p.lexer.position(tok.pos))
+	}
+	if tok.variant == semicolon {
+		jmp, err := p.lexer.next()
+		if err != nil {
+			return Compute{}, err
+		}
+		if _, ok := keywords[jmp.literal]; !ok {
+			return Compute{}, fmt.Errorf("%v: unexpected jump '%v'", p.lexer.position(jmp.pos), jmp.literal)
+		}
+		comp.Jump = jmp.literal
+		if err := p.terminate(); err != nil {
+			return Compute{}, err
+		}
+	}
+	return comp, nil
+}
+
+func (p *parser) seek(fn func(token) bool) error {
+	tok, err := p.lexer.peek()
+	if err != nil {
+		return err
+	}
+	for fn(tok) && tok.variant != eof {
+		_, err = p.lexer.next()
+		if err != nil {
+			return err
+		}
+		tok, err = p.lexer.peek()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// terminate asserts that the current instruction is followed by the end of its line, or the end of the source code,
+// and then skips past any empty lines that follow.
+func (p *parser) terminate() error {
+	tok, err := p.lexer.peek()
+	if err != nil {
+		return err
+	}
+	if tok.variant != linefeed && tok.variant != eof {
+		return fmt.Errorf("%v: unexpected '%v' at end of instruction", p.lexer.position(tok.pos), tok.literal)
+	}
+	return p.seek(p.clear)
+}
+
+func (p *parser) clear(tok token) bool {
+	return tok.variant == linefeed
+}
+
+// want asserts that the next token supplied by the lexer is of a given variant. If the lexer returns a different
+// variant than the one expected then an error is returned. If the expected token does appear then it is returned to the
+// caller.
+func (p *parser) want(v variant) (token, error) {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return token{}, err
+	}
+	if tok.variant != v {
+		return token{}, fmt.Errorf("%v: expected '%v' token but found '%v'", p.lexer.position(tok.pos), v, tok.variant)
+	}
+	return tok, nil
+}
@@ -0,0 +1,172 @@
+package asm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_next(t *testing.T) {
+	var assertions = []struct {
+		src string
+		res []Instruction
+	}{
+		{
+			src: "@17\n",
+			res: []Instruction{
+				Load{
+					Position: Pos{Offset: 0, Line: 1, Column: 1},
+					Value:    "17",
+				},
+			},
+		},
+		{
+			src: "A=D+1\n",
+			res: []Instruction{
+				Compute{
+					Position: Pos{Offset: 0, Line: 1, Column: 1},
+					Dest:     "A",
+					Comp:     "D+1",
+				},
+			},
+		},
+		{
+			src: "A;JGT\n",
+			res: []Instruction{
+				Compute{
+					Position: Pos{Offset: 0, Line: 1, Column: 1},
+					Comp:     "A",
+					Jump:     "JGT",
+				},
+			},
+		},
+		{
+			src: "@i\nD=A\nD=D+1;JNE\n",
+			res: []Instruction{
+				Load{
+					Position: Pos{Offset: 0, Line: 1, Column: 1},
+					Value:    "i",
+				},
+				Compute{
+					Position: Pos{Offset: 3, Line: 2, Column: 1},
+					Dest:     "D",
+					Comp:     "A",
+				},
+				Compute{
+					Position: Pos{Offset: 7, Line: 3, Column: 1},
+					Dest:     "D",
+					Comp:     "D+1",
+					Jump:     "JNE",
+				},
+			},
+		},
+		{
+			src: "(loop)\n@1234\nD=A+1\n@loop\n0;JMP\n",
+			res: []Instruction{
+				Label{
+					Position: Pos{Offset: 0, Line: 1, Column: 1},
+					Name:     "loop",
+				},
+				Load{
+					Position: Pos{Offset: 7, Line: 2, Column: 1},
+					Value:    "1234",
+				},
+				Compute{
+					Position: Pos{Offset: 13, Line: 3, Column: 1},
+					Dest:     "D",
+					Comp:     "A+1",
+				},
+				Load{
+					Position: Pos{Offset: 19, Line: 4, Column: 1},
+					Value:    "loop",
+				},
+				Compute{
+					Position: Pos{Offset: 25, Line: 5, Column: 1},
+					Comp:     "0",
+					Jump:     "JMP",
+				},
+			},
+		},
+		{
+			src: "  @2\n\n\t  D=!M",
+			res: []Instruction{
+				Load{
+					Position: Pos{Offset: 2, Line: 1, Column: 3},
+					Value:    "2",
+				},
+				Compute{
+					Position: Pos{Offset: 9, Line: 3, Column: 4},
+					Dest:     "D",
+					Comp:     "!M",
+				},
+			},
+		},
+	}
+	for _, assert := range assertions {
+		ps := parser{lexer: lexer{src: assert.src}}
+		ins, _ := ps.next()
+		for i := 0; ins != nil; i++ {
+			if !reflect.DeepEqual(assert.res[i], ins) {
+				t.Errorf("expected %+v but got %+v", assert.res[i], ins)
+			}
+			ins, _ = ps.next()
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("printing the parsed program yields equivalent source", func(t *testing.T) {
+		src := "(LOOP)\n@i\nM=M+1\nD=!M\n@LOOP\nD;JGT\nAMD=D|A;JMP\n"
+		program, err := Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var out bytes.Buffer
+		if err := Fprint(&out, program); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.String() != src {
+			t.Errorf("expected %q but got %q", src, out.String())
+		}
+	})
+	t.Run("errors carry the position of the offending token", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("@1\n(LOOP\n"))
+		if err == nil {
+			t.Fatal("expected error but got nil")
+		}
+		if !strings.HasPrefix(err.Error(), "2:6:") {
+			t.Errorf("expected error to be prefixed with position 2:6 but got %v", err)
+		}
+	})
+}
+
+// FuzzParse asserts that parsing terminates without panicking for any input and that every successfully parsed program
+// can be printed and parsed again into the same instructions.
+func FuzzParse(f *testing.F) {
+	f.Add("@17\nD=A\n")
+	f.Add("(LOOP)\r\n@LOOP\r\nD;JGT // done")
+	f.Add("/* header */\nAM=M-1 /* inline */\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		program, err := Parse(strings.NewReader(src))
+		if err != nil {
+			return
+		}
+		var out bytes.Buffer
+		if err := Fprint(&out, program); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		reparsed, err := Parse(&out)
+		if err != nil {
+			t.Fatalf("failed to parse printed program %q: %v", out.String(), err)
+		}
+		if len(reparsed) != len(program) {
+			t.Fatalf("expected %d instructions but got %d", len(program), len(reparsed))
+		}
+		for i := range program {
+			if program[i].Literal() != reparsed[i].Literal() {
+				t.Errorf("expected %q but got %q", program[i].Literal(), reparsed[i].Literal())
+			}
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package asm
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SourceMap maps every ROM address of an assembled program back to the source code of the instruction stored there. It
+// is written as JSON so that tools outside of this repository which generate Hack assembly, such as a VM translator,
+// can annotate the entries with what they know about the code they generated.
+type SourceMap struct {
+	// File is the name of the source file, if known
+	File string `json:"file,omitempty"`
+	// Sources holds the source of each instruction, indexed by ROM address
+	Sources []Source `json:"sources"`
+}
+
+// Source describes the source code of a single instruction.
+type Source struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Routine is the name of the label-delimited routine that contains the instruction, see NewRoutines. It is empty for
+	// instructions before the first label.
+	Routine string `json:"routine,omitempty"`
+	// Function, Call and Return describe the VM code that the instruction was translated from. They are never filled in
+	// by the assembler, or by any other tool in this repository, but are left for a VM translator to fill in. Function
+	// names the VM function that contains the instruction while Call and Return are set on the jump instructions which
+	// call a function and which return from one respectively.
+	Function string `json:"function,omitempty"`
+	Call     bool   `json:"call,omitempty"`
+	Return   bool   `json:"return,omitempty"`
+}
+
+// NewSourceMap creates the SourceMap of program, which was parsed from the source file called file.
+func NewSourceMap(file string, program []Instruction) *SourceMap {
+	m := &SourceMap{File: file}
+	routines := NewRoutines(Labels(program))
+	for _, ins := range program {
+		if _, ok := ins.(Label); ok {
+			continue
+		}
+		source := Source{Line: ins.Pos().Line, Column: ins.Pos().Column}
+		if r, ok := routines.Find(uint16(len(m.Sources))); ok {
+			source.Routine = r.Name
+		}
+		m.Sources = append(m.Sources, source)
+	}
+	return m
+}
+
+// ReadSourceMap reads a SourceMap written by SourceMap.Write.
+func ReadSourceMap(r io.Reader) (*SourceMap, error) {
+	var m SourceMap
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Write writes the SourceMap to w as JSON.
+func (m *SourceMap) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+// Lookup returns the source of the instruction at addr. False is returned if the map does not cover addr, which is
+// also the case for a nil SourceMap.
+func (m *SourceMap) Lookup(addr uint16) (Source, bool) {
+	if m == nil || int(addr) >= len(m.Sources) {
+		return Source{}, false
+	}
+	return m.Sources[addr], true
+}
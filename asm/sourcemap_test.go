@@ -0,0 +1,55 @@
+package asm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewSourceMap(t *testing.T) {
+	src := "@1\n(MAIN)\nD=A\n  @MAIN\n0;JMP\n"
+	bin, m, err := AssembleMapped("main.asm", strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &SourceMap{
+		File: "main.asm",
+		Sources: []Source{
+			{Line: 1, Column: 1},
+			{Line: 3, Column: 1, Routine: "MAIN"},
+			{Line: 4, Column: 3, Routine: "MAIN"},
+			{Line: 5, Column: 1, Routine: "MAIN"},
+		},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v but got %v", expected, m)
+	}
+	if len(m.Sources) != len(bin) {
+		t.Errorf("expected %d sources but got %d", len(bin), len(m.Sources))
+	}
+	t.Run("lookup", func(t *testing.T) {
+		if s, ok := m.Lookup(2); !ok || s != expected.Sources[2] {
+			t.Errorf("expected %v but got %v (%v)", expected.Sources[2], s, ok)
+		}
+		if _, ok := m.Lookup(4); ok {
+			t.Errorf("expected address 4 to be missing from the map")
+		}
+		if _, ok := (*SourceMap)(nil).Lookup(0); ok {
+			t.Errorf("expected address 0 to be missing from a nil map")
+		}
+	})
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := m.Write(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		actual, err := ReadSourceMap(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(actual, m) {
+			t.Errorf("expected %v but got %v", m, actual)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package asm
+
+import (
+	"slices"
+	"sort"
+)
+
+// Labels returns the ROM address that each label declared in program is bound to. Should a label be declared more than
+// once then its first declaration wins, just like when the program is assembled.
+func Labels(program []Instruction) map[string]uint16 {
+	labels := make(map[string]uint16)
+	addr := uint16(0)
+	for _, ins := range program {
+		switch v := ins.(type) {
+		case Label:
+			if _, ok := labels[v.Name]; !ok {
+				labels[v.Name] = addr
+			}
+		default:
+			addr++
+		}
+	}
+	return labels
+}
+
+// Routine is a range of ROM addresses that begins at a label and ends, exclusively, where the next label begins.
+type Routine struct {
+	Name string
+	// Aliases holds the other labels bound to Start, in sorted order
+	Aliases []string
+	Start   uint16
+	End     uint16
+}
+
+// Routines holds label-delimited routines ordered by their start address.
+type Routines []Routine
+
+// NewRoutines divides the ROM into routines delimited by labels. The last routine extends to the end of the ROM while
+// any instructions before the first label do not belong to a routine at all. When several labels are bound to the same
+// address the routine is named after the one that sorts first, while the others are kept as its aliases.
+func NewRoutines(labels map[string]uint16) Routines {
+	var routines Routines
+	for name, addr := range labels {
+		routines = append(routines, Routine{Name: name, Start: addr})
+	}
+	sort.Slice(routines, func(i, j int) bool {
+		if routines[i].Start != routines[j].Start {
+			return routines[i].Start < routines[j].Start
+		}
+		return routines[i].Name < routines[j].Name
+	})
+	unique := routines[:0]
+	for _, r := range routines {
+		if len(unique) > 0 && unique[len(unique)-1].Start == r.Start {
+			unique[len(unique)-1].Aliases = append(unique[len(unique)-1].Aliases, r.Name)
+			continue
+		}
+		unique = append(unique, r)
+	}
+	for i := range unique {
+		if i+1 < len(unique) {
+			unique[i].End = unique[i+1].Start
+		} else {
+			unique[i].End = 1 << 15
+		}
+	}
+	return unique
+}
+
+// Find returns the routine that contains the provided address.
+func (r Routines) Find(addr uint16) (Routine, bool) {
+	i := sort.Search(len(r), func(i int) bool {
+		return r[i].End > addr
+	})
+	if i == len(r) || r[i].Start > addr {
+		return Routine{}, false
+	}
+	return r[i], true
+}
+
+// Named returns the routine that begins at the label with the provided name, which is either its name or one of its
+// aliases.
+func (r Routines) Named(name string) (Routine, bool) {
+	for _, routine := range r {
+		if routine.Name == name || slices.Contains(routine.Aliases, name) {
+			return routine, true
+		}
+	}
+	return Routine{}, false
+}
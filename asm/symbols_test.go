@@ -0,0 +1,70 @@
+package asm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewRoutines(t *testing.T) {
+	src := "@1\n(MAIN)\n(START)\nD=A\n@LOOP\n(LOOP)\nD=D-1\n@LOOP\nD;JGT\n(END)\n@END\n0;JMP\n"
+	program, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	labels := Labels(program)
+	if !reflect.DeepEqual(labels, map[string]uint16{"MAIN": 1, "START": 1, "LOOP": 3, "END": 6}) {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	routines := NewRoutines(labels)
+	expected := Routines{
+		{Name: "MAIN", Aliases: []string{"START"}, Start: 1, End: 3},
+		{Name: "LOOP", Start: 3, End: 6},
+		{Name: "END", Start: 6, End: 32768},
+	}
+	if !reflect.DeepEqual(routines, expected) {
+		t.Errorf("expected %v but got %v", expected, routines)
+	}
+	var assertions = []struct {
+		addr uint16
+		name string
+		ok   bool
+	}{
+		{addr: 0, ok: false},
+		{addr: 1, name: "MAIN", ok: true},
+		{addr: 2, name: "MAIN", ok: true},
+		{addr: 3, name: "LOOP", ok: true},
+		{addr: 5, name: "LOOP", ok: true},
+		{addr: 6, name: "END", ok: true},
+		{addr: 32767, name: "END", ok: true},
+	}
+	for _, a := range assertions {
+		r, ok := routines.Find(a.addr)
+		if ok != a.ok || r.Name != a.name {
+			t.Errorf("expected address %d to be found in %q (%v) but got %q (%v)", a.addr, a.name, a.ok, r.Name, ok)
+		}
+	}
+}
+
+func TestRoutines_Named(t *testing.T) {
+	routines := NewRoutines(map[string]uint16{"MAIN": 1, "START": 1, "INIT": 1, "LOOP": 3})
+	var assertions = []struct {
+		name  string
+		start uint16
+		ok    bool
+	}{
+		{name: "INIT", start: 1, ok: true},
+		{name: "MAIN", start: 1, ok: true},
+		{name: "START", start: 1, ok: true},
+		{name: "LOOP", start: 3, ok: true},
+		{name: "MISSING", ok: false},
+	}
+	for _, a := range assertions {
+		t.Run(a.name, func(t *testing.T) {
+			r, ok := routines.Named(a.name)
+			if ok != a.ok || r.Start != a.start {
+				t.Errorf("expected routine at %d (%v) but got %d (%v)", a.start, a.ok, r.Start, ok)
+			}
+		})
+	}
+}
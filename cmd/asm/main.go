@@ -3,31 +3,49 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/crookdc/nand2tetris/internal/asm"
+	"github.com/crookdc/nand2tetris/asm"
 	"log"
 	"os"
 )
 
-var source = flag.String("source", "", "a file containing Hack assembly code")
+var (
+	source    = flag.String("source", "", "a file containing Hack assembly code")
+	sourceMap = flag.String("source-map", "", "write the source map of the program to this file")
+	devices   = flag.Bool("devices", false, "predefine the symbols of the device registers of the simulator, such as RANDOM")
+)
 
 func main() {
 	flag.Parse()
 	if *source == "" {
 		log.Fatal("no source file provided")
 	}
-	src, err := os.ReadFile(*source)
+	src, err := os.Open(*source)
 	if err != nil {
 		log.Fatal(err)
 	}
-	program, err := asm.Assemble(string(src))
+	defer src.Close()
+	program, m, err := asm.Options{Devices: *devices}.AssembleMapped(*source, src)
 	if err != nil {
 		log.Fatal(err)
 	}
-	for _, ins := range program {
-		var mc string
-		for i := range 16 {
-			mc += fmt.Sprintf("%v", ins[i])
+	if *sourceMap != "" {
+		if err := writeSourceMap(*sourceMap, m); err != nil {
+			log.Fatal(err)
 		}
-		fmt.Println(mc)
 	}
+	for _, ins := range program {
+		fmt.Printf("%016b\n", ins)
+	}
+}
+
+func writeSourceMap(file string, m *asm.SourceMap) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := m.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"github.com/crookdc/nand2tetris/internal/simulator"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	serialLog = flag.String("serial-log", "", "write the output of the serial console to this file instead of stdout")
+	disk      = flag.String("disk", "", "file holding the sectors of the disk, which is created if it does not exist")
+	wav       = flag.String("wav", "", "record the sound of the tone generator to this WAV file, requires -headless")
+	seed      = flag.String("seed", "", "seed of the random number generator, which is otherwise seeded from the current time")
+)
+
+// attachSerial attaches a serial console which receives from stdin and sends to stdout, or to the file given by the
+// serial-log flag. The returned function closes the file, if any.
+func attachSerial(sim simulation) (func() error, error) {
+	var w io.Writer = os.Stdout
+	closeLog := func() error { return nil }
+	if *serialLog != "" {
+		f, err := os.Create(*serialLog)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+		closeLog = f.Close
+	}
+	if err := sim.Attach("serial", device.SerialAddress, device.SerialSize, device.NewSerial(os.Stdin, w)); err != nil {
+		_ = closeLog()
+		return nil, err
+	}
+	return closeLog, nil
+}
+
+// attachRandom attaches a random number generator seeded by the seed flag, or by the current time if it is not set.
+func attachRandom(sim simulation) error {
+	s := uint64(time.Now().UnixNano())
+	if *seed != "" {
+		var err error
+		s, err = strconv.ParseUint(*seed, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed '%s'", *seed)
+		}
+	}
+	return sim.Attach("random", device.RandomAddress, device.RandomSize, device.NewRandom(s))
+}
+
+// attachDisk attaches a disk stored in the file given by the disk flag, unless it is not set. The returned function
+// closes the file.
+func attachDisk(sim simulation) (func() error, error) {
+	if *disk == "" {
+		return func() error { return nil }, nil
+	}
+	f, err := os.OpenFile(*disk, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if err := sim.Attach("disk", device.DiskAddress, device.DiskSize, device.NewDisk(f)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f.Close, nil
+}
+
+// attachTone attaches a tone generator, which is played through the audio device of the SDL simulator or recorded to the
+// file given by the wav flag in headless mode. The returned function completes the recording, if any.
+func attachTone(sim simulation) (func() error, error) {
+	tone := device.NewTone()
+	if err := sim.Attach("tone", device.ToneAddress, device.ToneSize, tone); err != nil {
+		return nil, err
+	}
+	switch s := sim.(type) {
+	case *simulator.SDLSimulator:
+		if *wav != "" {
+			return nil, errors.New("recording sound requires -headless")
+		}
+		if err := s.Play(tone); err != nil {
+			// The program can still be run without sound
+			log.Printf("no sound: %v", err)
+		}
+	case *simulator.Headless:
+		if *wav == "" {
+			break
+		}
+		f, err := os.Create(*wav)
+		if err != nil {
+			return nil, err
+		}
+		w, err := simulator.NewWAVWriter(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		s.Record(tone, w)
+		return func() error {
+			if err := w.Close(); err != nil {
+				_ = f.Close()
+				return err
+			}
+			return f.Close()
+		}, nil
+	}
+	return func() error { return nil }, nil
+}
@@ -2,21 +2,56 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"github.com/crookdc/nand2tetris/asm"
 	"github.com/crookdc/nand2tetris/internal/chip"
+	hackprof "github.com/crookdc/nand2tetris/internal/profile"
 	"github.com/crookdc/nand2tetris/internal/simulator"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
 	"runtime/pprof"
 	"strconv"
 )
 
 var (
-	profile = flag.String("profile", "", "write profiling data to files with this base name")
-	program = flag.String("program", "", "file containing program to be written to rom")
+	profile     = flag.String("profile", "", "write profiling data to files with this base name")
+	hackProfile = flag.String("hack-profile", "", "write a pprof profile of the Hack program to this file, attributed to the source code described by -source-map or -source")
+	program     = flag.String("program", "", "file containing program to be written to rom")
+	fill        = flag.String("fill", "", "instruction executed beyond the end of the program instead of halting, written as 16 binary digits")
+	clock       = flag.String("clock", "unlimited", "clock rate in instructions per second, or unlimited to run as fast as possible")
+	scale       = flag.Int("scale", 1, "initial size of a screen pixel in pixels of the window")
+	palette     = flag.String("palette", "white-on-black", "colors of the screen, either white-on-black or black-on-white")
+	fullscreen  = flag.Bool("fullscreen", false, "start in fullscreen")
+	scanlines   = flag.Bool("scanlines", false, "draw dark lines between rows of pixels like a CRT monitor, requires a scale of at least 2")
+	keymap      = flag.String("keymap", "", "file containing a keymap which extends the default mapping of keys to Hack key codes")
+	keys        = flag.String("keyboard", "classic", "keyboard mode, either classic for a single register or queued for a queue of pressed keys acknowledged by writes")
+	source      = flag.String("source", "", "file containing the assembly source code of the program, used to resolve labels")
+	sourceMap   = flag.String("source-map", "", "file containing the source map of the program as written by the assembler, used instead of -source to profile the program")
+	headless    = flag.Bool("headless", false, "run without a window until the program halts or the process is interrupted")
+	link        = flag.String("link", "", "file containing the program of a second computer, linked to the first through their UARTs")
+	lockstep    = flag.Bool("lockstep", false, "run the linked computers in lockstep rather than independently of each other")
 )
 
+// simulation is implemented by both simulator.SDLSimulator and simulator.Headless
+type simulation interface {
+	Fill(instr [16]chip.Signal)
+	Trace(t chip.Tracer)
+	Snapshot() chip.Snapshot
+	Restore(snapshot chip.Snapshot) error
+	Attach(name string, start uint16, size int, device chip.Device) error
+	SetClockRate(hz uint64)
+	Run(ctx context.Context) error
+}
+
+func init() {
+	// SDL must only ever be called from the main OS thread, which is the thread that runs init and thereby main
+	runtime.LockOSThread()
+}
+
 func main() {
 	flag.Parse()
 	if *profile != "" {
@@ -38,13 +73,111 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	sim, err := simulator.NewSDLSimulator(rom)
+	var sim simulation
+	if *headless {
+		if *link != "" {
+			log.Fatal("linking a second computer requires a window")
+		}
+		sim = simulator.NewHeadless(rom)
+	} else {
+		s, err := newSDLSimulator(rom)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer s.Close()
+		sim = s
+	}
+	closeSerial, err := attachSerial(sim)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer sim.Close()
-	for sim.Running {
-		sim.Update()
+	defer func() {
+		if err := closeSerial(); err != nil {
+			log.Println(err)
+		}
+	}()
+	if err := attachRandom(sim); err != nil {
+		log.Fatal(err)
+	}
+	closeDisk, err := attachDisk(sim)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := closeDisk(); err != nil {
+			log.Println(err)
+		}
+	}()
+	closeTone, err := attachTone(sim)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := closeTone(); err != nil {
+			log.Println(err)
+		}
+	}()
+	rate, err := parseClockRate(*clock)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sim.SetClockRate(rate)
+	if *fill != "" {
+		instruction, err := parseInstruction(*fill)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sim.Fill(instruction)
+	}
+	if *restore != "" {
+		s, err := readSnapshot(*restore)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := sim.Restore(s); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var tracers chip.Tracers
+	if *traceFile != "" {
+		tracer, err := openTrace()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			if err := tracer.Close(); err != nil {
+				log.Println(err)
+			}
+		}()
+		tracers = append(tracers, tracer)
+	}
+	var profiler *hackprof.Profiler
+	if *hackProfile != "" {
+		m, err := loadSourceMap()
+		if err != nil {
+			log.Fatal(err)
+		}
+		profiler = hackprof.New(m)
+		tracers = append(tracers, profiler)
+	}
+	if len(tracers) > 0 {
+		sim.Trace(tracers)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// Halting is how most programs end, so the error is reported but the state of the computer is still saved
+	if err := sim.Run(ctx); err != nil {
+		log.Println(err)
+	}
+	if *snapshot != "" {
+		if err := writeSnapshot(*snapshot, sim.Snapshot()); err != nil {
+			log.Println(err)
+		}
+	}
+	if profiler != nil {
+		if err := writeProfile(profiler, *hackProfile); err != nil {
+			log.Println(err)
+		}
 	}
 
 	if *profile != "" {
@@ -59,6 +192,116 @@ func main() {
 	}
 }
 
+// newSDLSimulator opens the window of the simulator as configured by the flags
+func newSDLSimulator(rom chip.ROM) (*simulator.SDLSimulator, error) {
+	colors, err := simulator.ParsePalette(*palette)
+	if err != nil {
+		return nil, err
+	}
+	sim, err := simulator.NewSDLSimulator(rom, simulator.ScreenOptions{
+		Scale:      *scale,
+		Palette:    colors,
+		Fullscreen: *fullscreen,
+		Scanlines:  *scanlines,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if *keymap != "" {
+		k, err := loadKeymap(*keymap)
+		if err != nil {
+			sim.Close()
+			return nil, err
+		}
+		sim.SetKeymap(k)
+	}
+	switch *keys {
+	case "classic":
+	case "queued":
+		if err := sim.QueueKeys(); err != nil {
+			sim.Close()
+			return nil, err
+		}
+	default:
+		sim.Close()
+		return nil, fmt.Errorf("unknown keyboard mode '%s'", *keys)
+	}
+	if *link != "" {
+		linked, err := loadProgram(*link)
+		if err != nil {
+			sim.Close()
+			return nil, err
+		}
+		if err := sim.Link(linked, *lockstep); err != nil {
+			sim.Close()
+			return nil, err
+		}
+	}
+	if *snapshot != "" {
+		sim.OnSnapshot = func(s chip.Snapshot) {
+			if err := writeSnapshot(*snapshot, s); err != nil {
+				log.Println(err)
+				return
+			}
+			log.Printf("saved snapshot to %s", *snapshot)
+		}
+	}
+	return sim, nil
+}
+
+// loadSource parses the assembly source code provided through the source flag. Without a source file there is no
+// program to return.
+func loadSource() ([]asm.Instruction, error) {
+	if *source == "" {
+		return nil, nil
+	}
+	f, err := os.Open(*source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return asm.Parse(f)
+}
+
+// loadRoutines returns the label-delimited routines of the assembly source code provided through the source flag.
+func loadRoutines() (asm.Routines, error) {
+	program, err := loadSource()
+	if err != nil {
+		return nil, err
+	}
+	return asm.NewRoutines(asm.Labels(program)), nil
+}
+
+// loadSourceMap reads the source map provided through the source-map flag or, failing that, creates one from the
+// assembly source code provided through the source flag. Without either there is no source map to return.
+func loadSourceMap() (*asm.SourceMap, error) {
+	if *sourceMap == "" {
+		program, err := loadSource()
+		if err != nil || program == nil {
+			return nil, err
+		}
+		return asm.NewSourceMap(*source, program), nil
+	}
+	f, err := os.Open(*sourceMap)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return asm.ReadSourceMap(f)
+}
+
+func writeProfile(profiler *hackprof.Profiler, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := profiler.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func loadProgram(file string) (chip.ROM, error) {
 	f, err := os.OpenFile(file, os.O_RDONLY, 0666)
 	if err != nil {
@@ -69,8 +312,8 @@ func loadProgram(file string) (chip.ROM, error) {
 	s := bufio.NewScanner(f)
 	for s.Scan() {
 		line := s.Text()
-		if len(line) != 16 {
-			return nil, fmt.Errorf("invalid line length '%s'", line)
+		if len(rom) == chip.ROMSize {
+			return nil, fmt.Errorf("program exceeds the ROM capacity of %d instructions", chip.ROMSize)
 		}
 		instruction, err := parseInstruction(line)
 		if err != nil {
@@ -81,13 +324,39 @@ func loadProgram(file string) (chip.ROM, error) {
 	return rom, nil
 }
 
+func loadKeymap(file string) (*simulator.Keymap, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return simulator.LoadKeymap(f)
+}
+
+func parseClockRate(rate string) (uint64, error) {
+	if rate == "unlimited" {
+		return simulator.Unlimited, nil
+	}
+	hz, err := strconv.ParseUint(rate, 10, 64)
+	if err != nil || hz == 0 {
+		return 0, fmt.Errorf("invalid clock rate '%s'", rate)
+	}
+	return hz, nil
+}
+
 func parseInstruction(line string) ([16]chip.Signal, error) {
+	if len(line) != 16 {
+		return [16]chip.Signal{}, fmt.Errorf("invalid line length '%s'", line)
+	}
 	instruction := [16]chip.Signal{}
 	for i := range 16 {
 		bit, err := strconv.Atoi(string(line[i]))
 		if err != nil {
 			return [16]chip.Signal{}, err
 		}
+		if bit > 1 {
+			return [16]chip.Signal{}, fmt.Errorf("invalid binary digit '%d' in '%s'", bit, line)
+		}
 		instruction[i] = chip.Signal(bit)
 	}
 	return instruction, nil
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"os"
+	"path/filepath"
+)
+
+var (
+	restore  = flag.String("restore", "", "restore the state of the computer from this snapshot file before running")
+	snapshot = flag.String("snapshot", "", "save a snapshot of the computer to this file on exit and whenever Print Screen is pressed")
+)
+
+// readSnapshot reads a snapshot from file, which is decoded as JSON if its extension is .json and in the binary snapshot
+// format otherwise.
+func readSnapshot(file string) (chip.Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return chip.Snapshot{}, err
+	}
+	var s chip.Snapshot
+	if filepath.Ext(file) == ".json" {
+		err = json.Unmarshal(data, &s)
+	} else {
+		err = s.UnmarshalBinary(data)
+	}
+	return s, err
+}
+
+// writeSnapshot writes s to file in the format given by its extension, see readSnapshot.
+func writeSnapshot(file string, s chip.Snapshot) error {
+	var data []byte
+	var err error
+	if filepath.Ext(file) == ".json" {
+		data, err = json.Marshal(s)
+	} else {
+		data, err = s.MarshalBinary()
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0666)
+}
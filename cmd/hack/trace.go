@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/trace"
+	"os"
+	"strings"
+)
+
+var (
+	traceFile   = flag.String("trace", "", "write an execution trace of the program to this file")
+	traceFormat = flag.String("trace-format", "jsonl", "format of the execution trace, either jsonl or chrome")
+	tracePC     = flag.String("trace-pc", "", "only trace instructions within this inclusive range of ROM addresses, e.g. 16-32")
+	traceLabel  = flag.String("trace-label", "", "only trace instructions within these comma separated routines, requires -source")
+)
+
+type tracer interface {
+	chip.Tracer
+	Close() error
+}
+
+// closer pairs the Tracer installed in the computer with the file written to by the underlying trace writer
+type closer struct {
+	chip.Tracer
+	close func() error
+}
+
+func (c closer) Close() error {
+	return c.close()
+}
+
+// openTrace creates the trace file and a Tracer which writes to it in the requested format, wrapped in a filter when
+// any filter flags have been provided.
+func openTrace() (tracer, error) {
+	routines, err := loadRoutines()
+	if err != nil {
+		return nil, err
+	}
+	filter := &trace.Filter{}
+	if *tracePC != "" {
+		var first, last uint16
+		if _, err := fmt.Sscanf(*tracePC, "%d-%d", &first, &last); err != nil || first > last {
+			return nil, fmt.Errorf("invalid trace range '%s'", *tracePC)
+		}
+		filter.Ranges = append(filter.Ranges, trace.Range{First: first, Last: last})
+	}
+	if *traceLabel != "" {
+		if *source == "" {
+			return nil, fmt.Errorf("tracing by label requires the -source flag")
+		}
+		for _, label := range strings.Split(*traceLabel, ",") {
+			if !filter.Routine(routines, label) {
+				return nil, fmt.Errorf("label '%s' not found in %s", label, *source)
+			}
+		}
+	}
+	f, err := os.Create(*traceFile)
+	if err != nil {
+		return nil, err
+	}
+	var writer tracer
+	switch *traceFormat {
+	case "jsonl":
+		writer = trace.NewJSONLines(f, routines)
+	case "chrome":
+		writer = trace.NewChrome(f, routines)
+	default:
+		_ = f.Close()
+		return nil, fmt.Errorf("unknown trace format '%s'", *traceFormat)
+	}
+	filter.Tracer = writer
+	return closer{
+		Tracer: filter,
+		close: func() error {
+			if err := writer.Close(); err != nil {
+				_ = f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}, nil
+}
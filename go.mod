@@ -2,4 +2,7 @@ module github.com/crookdc/nand2tetris
 
 go 1.23.4
 
-require github.com/veandco/go-sdl2 v0.4.40 // indirect
+require (
+	github.com/google/pprof v0.0.0-20250403155104-27863c87afa6
+	github.com/veandco/go-sdl2 v0.4.40
+)
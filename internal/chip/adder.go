@@ -24,3 +24,8 @@ func Adder16(a, b ReadonlyWord) *Word {
 	}
 	return r
 }
+
+// Inc16 adds one to in, wrapping around from the largest value to zero.
+func Inc16(in ReadonlyWord) *Word {
+	return Adder16(in, Wrap(&[16]Signal{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, Active}))
+}
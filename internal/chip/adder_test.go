@@ -176,3 +176,12 @@ func TestAdder16(t *testing.T) {
 		}
 	}
 }
+
+func TestInc16(t *testing.T) {
+	for v := range 1 << 16 {
+		want := uint16(v) + 1
+		if r := Inc16(WrapUint16(uint16(v))).Uint16(); r != want {
+			t.Fatalf("expected %d with %d but got %d", want, v, r)
+		}
+	}
+}
@@ -28,6 +28,7 @@ func (a *ALU) Out(x, y ReadonlyWord) (out *Word, zr Signal, ng Signal) {
 	out = Mux2Way16(a.F, And16(x, y), Adder16(x, y))
 	out = Xor16To1(a.NO, out)
 	ng = out.Get(0) // If the MSB is 1 then the value is negative as per the rules of two's complement
-	zr = Not(Or(out.Get(0), Or(out.Get(1), Or(out.Get(2), Or(out.Get(3), Or(out.Get(4), Or(out.Get(5), Or(out.Get(6), Or(out.Get(7), Or(out.Get(8), Or(out.Get(9), Or(out.Get(10), Or(out.Get(11), Or(out.Get(12), Or(out.Get(13), Or(out.Get(14), out.Get(15)))))))))))))))))
+	bits := out.Copy()
+	zr = Not(Or(Or8Way([8]Signal(bits[:8])), Or8Way([8]Signal(bits[8:]))))
 	return
 }
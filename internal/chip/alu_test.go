@@ -492,3 +492,17 @@ func TestALU_Call(t *testing.T) {
 		})
 	})
 }
+
+func TestALU_flags(t *testing.T) {
+	// x&-1 passes x through to the output, so the flags are checked against every possible output
+	alu := ALU{ZY: Active, NY: Active}
+	for x := range 1 << 16 {
+		out, zr, ng := alu.Out(WrapUint16(uint16(x)), NullWord)
+		if out.Uint16() != uint16(x) {
+			t.Fatalf("expected %d but got %d", x, out.Uint16())
+		}
+		if (zr == Active) != (x == 0) || (ng == Active) != (x >= 0x8000) {
+			t.Fatalf("expected zr %v and ng %v for %d but got %v and %v", x == 0, x >= 0x8000, x, zr, ng)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package chip
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The memory map of the Hack platform
+const (
+	ScreenAddress   = 16384
+	ScreenSize      = 8192
+	KeyboardAddress = 24576
+)
+
+// ErrOverlap is returned by Bus.Attach when a device would be mapped to addresses that are already taken.
+var ErrOverlap = errors.New("address range overlaps")
+
+// Device is a peripheral which is mapped into a range of addresses by a Bus. Addresses are passed to the device as
+// offsets from the start of its range.
+//
+// The Computer reads the word addressed by the A register exactly once per instruction, whether the instruction uses it
+// or not and also when it writes to it, just like the hardware it simulates. A device whose reads have side effects,
+// such as advancing a sequence, therefore sees one read for every instruction executed while A addresses it.
+type Device interface {
+	Read(offset uint16) uint16
+	Write(offset uint16, value uint16)
+}
+
+// Callbacks adapts a pair of functions to the Device interface. Reads return zero when OnRead is nil and writes are
+// ignored when OnWrite is nil.
+type Callbacks struct {
+	OnRead  func(offset uint16) uint16
+	OnWrite func(offset uint16, value uint16)
+}
+
+func (c Callbacks) Read(offset uint16) uint16 {
+	if c.OnRead == nil {
+		return 0
+	}
+	return c.OnRead(offset)
+}
+
+func (c Callbacks) Write(offset uint16, value uint16) {
+	if c.OnWrite != nil {
+		c.OnWrite(offset, value)
+	}
+}
+
+// Block is a Device of plain read-write memory, such as the RAM or the screen of the Hack platform.
+type Block []uint16
+
+func (b Block) Read(offset uint16) uint16 {
+	return b[offset]
+}
+
+func (b Block) Write(offset uint16, value uint16) {
+	b[offset] = value
+}
+
+// Keyboard is a Device holding the code of a key. By default it holds the code of the key that is currently pressed, or
+// zero if no key is pressed, and writes are ignored.
+//
+// In queued mode, enabled by Queue, it instead holds the oldest code of a queue of pressed keys, or zero if the queue is
+// empty, and writing any value to it acknowledges that code by removing it from the queue. Releasing a key is not
+// queued, so that keys pressed and released in between two reads of the program are never lost.
+type Keyboard struct {
+	code uint16
+	// queue holds the codes that have been pressed but not yet acknowledged in queued mode, with at most limit codes
+	queue []uint16
+	limit int
+}
+
+// Queue switches the keyboard to queued mode, where up to size codes are held until acknowledged. Further codes are
+// dropped while the queue is full.
+func (k *Keyboard) Queue(size int) {
+	k.limit = size
+	k.queue = make([]uint16, 0, size)
+}
+
+// Queued returns the number of codes waiting to be acknowledged in queued mode.
+func (k *Keyboard) Queued() int {
+	return len(k.queue)
+}
+
+// Press sets the code of the key that is currently pressed, or queues it in queued mode.
+func (k *Keyboard) Press(code uint16) {
+	if k.limit == 0 {
+		k.code = code
+		return
+	}
+	if code != 0 && len(k.queue) < k.limit {
+		k.queue = append(k.queue, code)
+	}
+}
+
+func (k *Keyboard) Read(uint16) uint16 {
+	if k.limit == 0 {
+		return k.code
+	}
+	if len(k.queue) == 0 {
+		return 0
+	}
+	return k.queue[0]
+}
+
+func (k *Keyboard) Write(uint16, uint16) {
+	if len(k.queue) > 0 {
+		k.queue = append(k.queue[:0], k.queue[1:]...)
+	}
+}
+
+// Out lets the Keyboard be used as a Memory holding a single word regardless of the address, where loading a word is
+// handled like a call to Write.
+func (k *Keyboard) Out(load Signal, _ [15]Signal, _ ReadonlyWord) *Word {
+	if load == Active {
+		k.Write(0, 0)
+	}
+	return WrapUint16(k.Read(0))
+}
+
+// mapping is a range of addresses from start up to, but not including, end which is routed to a device
+type mapping struct {
+	name   string
+	start  int
+	end    int
+	device Device
+}
+
+// Bus is a Memory which routes every address to the Device mapped to it. Reading an address that no device is mapped to
+// yields zero and writing to one does nothing.
+type Bus struct {
+	mappings []mapping
+	// routes holds, for every address, one more than the index of the mapping it belongs to or zero if it is unmapped
+	routes [RAMSize]uint8
+}
+
+// NewHackBus creates a Bus with the memory map of the Hack platform: 16K words of RAM followed by the screen and the
+// keyboard.
+func NewHackBus(keyboard *Keyboard) *Bus {
+	b := &Bus{}
+	// None of these can overlap, so there are no errors to handle
+	_ = b.Attach("ram", 0, ScreenAddress, make(Block, ScreenAddress))
+	_ = b.Attach("screen", ScreenAddress, ScreenSize, make(Block, ScreenSize))
+	_ = b.Attach("keyboard", KeyboardAddress, 1, keyboard)
+	return b
+}
+
+// Attach maps size words of addresses, starting at start, to device. An error is returned if the range does not fit in
+// the address space or overlaps with a device that is already attached.
+func (b *Bus) Attach(name string, start uint16, size int, device Device) error {
+	end := int(start) + size
+	if size <= 0 || end > RAMSize {
+		return fmt.Errorf("device %s at %d with size %d does not fit in the address space", name, start, size)
+	}
+	if len(b.mappings) == 255 {
+		return fmt.Errorf("device %s cannot be attached as the bus is full", name)
+	}
+	for _, m := range b.mappings {
+		if int(start) < m.end && m.start < end {
+			return fmt.Errorf("%w: device %s at %d-%d overlaps with %s at %d-%d", ErrOverlap, name, start, end-1, m.name, m.start, m.end-1)
+		}
+	}
+	b.mappings = append(b.mappings, mapping{name: name, start: int(start), end: end, device: device})
+	for addr := int(start); addr < end; addr++ {
+		b.routes[addr] = uint8(len(b.mappings))
+	}
+	return nil
+}
+
+// Device returns the device attached under name.
+func (b *Bus) Device(name string) (Device, bool) {
+	for _, m := range b.mappings {
+		if m.name == name {
+			return m.device, true
+		}
+	}
+	return nil, false
+}
+
+// Peek reads the word at addr without involving any device other than a Block, such that reading has no side effects.
+// False is returned if addr is not mapped to a Block.
+func (b *Bus) Peek(addr uint16) (uint16, bool) {
+	block, offset, ok := b.block(addr)
+	if !ok {
+		return 0, false
+	}
+	return block[offset], true
+}
+
+// Poke writes value to addr without involving any device other than a Block, such that writing has no side effects.
+// False is returned, and nothing is written, if addr is not mapped to a Block.
+func (b *Bus) Poke(addr uint16, value uint16) bool {
+	block, offset, ok := b.block(addr)
+	if ok {
+		block[offset] = value
+	}
+	return ok
+}
+
+// block returns the Block mapped to addr along with the offset of addr within it
+func (b *Bus) block(addr uint16) (Block, uint16, bool) {
+	route := b.routes[addr&0x7FFF]
+	if route == 0 {
+		return nil, 0, false
+	}
+	m := b.mappings[route-1]
+	block, ok := m.device.(Block)
+	return block, addr&0x7FFF - uint16(m.start), ok
+}
+
+// Out reads from, or writes to, the device mapped to addr. Writes return the written word rather than reading it back
+// from the device.
+func (b *Bus) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	idx := Join15(addr)
+	route := b.routes[idx]
+	if load == Active {
+		word := in.Copy()
+		if route != 0 {
+			m := b.mappings[route-1]
+			m.device.Write(idx-uint16(m.start), Wrap(&word).Uint16())
+		}
+		return Wrap(&word)
+	}
+	if route == 0 {
+		return NewWord()
+	}
+	m := b.mappings[route-1]
+	return WrapUint16(m.device.Read(idx - uint16(m.start)))
+}
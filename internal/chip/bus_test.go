@@ -0,0 +1,214 @@
+package chip
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBus_Attach(t *testing.T) {
+	var assertions = []struct {
+		name  string
+		start uint16
+		size  int
+		err   bool
+	}{
+		{name: "free range", start: 24577, size: 16},
+		{name: "overlaps the start of the screen", start: 16000, size: 1000, err: true},
+		{name: "overlaps the keyboard", start: 24570, size: 7, err: true},
+		{name: "exceeds the address space", start: 32760, size: 9, err: true},
+		{name: "empty", start: 30000, size: 0, err: true},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			b := NewHackBus(&Keyboard{})
+			err := b.Attach("device", assert.start, assert.size, Block(make([]uint16, max(assert.size, 0))))
+			if (err != nil) != assert.err {
+				t.Errorf("expected error %v but got %v", assert.err, err)
+			}
+		})
+	}
+	t.Run("overlaps are reported as ErrOverlap", func(t *testing.T) {
+		err := NewHackBus(&Keyboard{}).Attach("device", 0, 1, Callbacks{})
+		if !errors.Is(err, ErrOverlap) {
+			t.Errorf("expected %v but got %v", ErrOverlap, err)
+		}
+	})
+}
+
+func TestBus_Out(t *testing.T) {
+	keyboard := &Keyboard{}
+	b := NewHackBus(keyboard)
+	var written []uint16
+	err := b.Attach("device", 24600, 4, Callbacks{
+		OnRead: func(offset uint16) uint16 {
+			return 100 + offset
+		},
+		OnWrite: func(offset uint16, value uint16) {
+			written = append(written, offset, value)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	read := func(addr uint16) uint16 {
+		return b.Out(Inactive, split15(addr), NullWord).Uint16()
+	}
+	write := func(addr uint16, value uint16) {
+		b.Out(Active, split15(addr), WrapUint16(value))
+	}
+
+	write(17, 1234)
+	write(ScreenAddress+1, 0xFFFF)
+	write(24602, 7)
+	write(30000, 9)
+	keyboard.Press('K')
+	var assertions = []struct {
+		name string
+		addr uint16
+		want uint16
+	}{
+		{name: "ram", addr: 17, want: 1234},
+		{name: "screen", addr: ScreenAddress + 1, want: 0xFFFF},
+		{name: "keyboard", addr: KeyboardAddress, want: 'K'},
+		{name: "device", addr: 24603, want: 103},
+		{name: "unmapped", addr: 30000, want: 0},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			if actual := read(assert.addr); actual != assert.want {
+				t.Errorf("expected %d but got %d", assert.want, actual)
+			}
+		})
+	}
+	if len(written) != 2 || written[0] != 2 || written[1] != 7 {
+		t.Errorf("expected a single write of 7 to offset 2 but got %v", written)
+	}
+	t.Run("keyboard is read-only", func(t *testing.T) {
+		write(KeyboardAddress, 1)
+		if actual := read(KeyboardAddress); actual != 'K' {
+			t.Errorf("expected %d but got %d", 'K', actual)
+		}
+	})
+}
+
+func TestBus_Peek(t *testing.T) {
+	b := NewHackBus(&Keyboard{})
+	var accessed bool
+	err := b.Attach("device", 24577, 1, Callbacks{
+		OnRead: func(uint16) uint16 {
+			accessed = true
+			return 1
+		},
+		OnWrite: func(uint16, uint16) { accessed = true },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var assertions = []struct {
+		name  string
+		addr  uint16
+		plain bool
+	}{
+		{name: "ram", addr: 17, plain: true},
+		{name: "screen", addr: ScreenAddress + 1, plain: true},
+		{name: "keyboard", addr: KeyboardAddress},
+		{name: "device", addr: 24577},
+		{name: "unmapped", addr: 30000},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			if ok := b.Poke(assert.addr, 1234); ok != assert.plain {
+				t.Errorf("expected poke to return %v but got %v", assert.plain, ok)
+			}
+			expected := uint16(0)
+			if assert.plain {
+				expected = 1234
+			}
+			if v, ok := b.Peek(assert.addr); ok != assert.plain || v != expected {
+				t.Errorf("expected %d (%v) but got %d (%v)", expected, assert.plain, v, ok)
+			}
+			if accessed {
+				t.Errorf("expected the device not to be accessed")
+			}
+		})
+	}
+}
+
+func TestComputer_bus(t *testing.T) {
+	var value uint16
+	b := NewHackBus(&Keyboard{})
+	if err := b.Attach("device", 24577, 1, Callbacks{OnWrite: func(_ uint16, v uint16) { value = v }}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewComputerWithRAM(ROM{
+		split16(24577),                 // @24577
+		split16(0b1110_1111_1100_1000), // M=1
+	}, b)
+	tick(t, c, 2)
+	if value != 1 {
+		t.Errorf("expected device to be written 1 but got %d", value)
+	}
+}
+
+// TestComputer_busReads checks that the device addressed by the A register is read exactly once per instruction
+func TestComputer_busReads(t *testing.T) {
+	var reads, writes int
+	b := NewHackBus(&Keyboard{})
+	device := Callbacks{
+		OnRead: func(uint16) uint16 {
+			reads++
+			return 0
+		},
+		OnWrite: func(uint16, uint16) {
+			writes++
+		},
+	}
+	if err := b.Attach("device", 24577, 1, device); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewComputerWithRAM(ROM{
+		split16(24577),                 // @24577
+		split16(0b1111_1100_0001_0000), // D=M
+		split16(0b1110_0111_1101_0000), // D=D+1
+		split16(0b1110_0011_0000_1000), // M=D
+	}, b)
+	tick(t, c, 4)
+	if reads != 3 {
+		t.Errorf("expected %d reads but got %d", 3, reads)
+	}
+	if writes != 1 {
+		t.Errorf("expected %d writes but got %d", 1, writes)
+	}
+}
+
+func TestKeyboard_Queue(t *testing.T) {
+	k := &Keyboard{}
+	k.Queue(2)
+	expect := func(t *testing.T, code uint16, queued int) {
+		t.Helper()
+		if actual := k.Read(0); actual != code || k.Queued() != queued {
+			t.Errorf("expected %d with %d queued but got %d with %d queued", code, queued, actual, k.Queued())
+		}
+	}
+	t.Run("empty", func(t *testing.T) {
+		expect(t, 0, 0)
+	})
+	t.Run("releases are not queued", func(t *testing.T) {
+		k.Press('a')
+		k.Press(0)
+		expect(t, 'a', 1)
+	})
+	t.Run("codes are dropped while the queue is full", func(t *testing.T) {
+		k.Press('b')
+		k.Press('c')
+		expect(t, 'a', 2)
+	})
+	t.Run("writes acknowledge the oldest code", func(t *testing.T) {
+		k.Write(0, 0)
+		expect(t, 'b', 1)
+		k.Write(0, 0)
+		expect(t, 0, 0)
+		k.Write(0, 0)
+		expect(t, 0, 0)
+	})
+}
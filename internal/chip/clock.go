@@ -0,0 +1,61 @@
+package chip
+
+// Sequential is implemented by chips which hold state from one clock cycle to the next. Every cycle consists of two
+// phases: on the tick a chip computes its next state from its inputs while its outputs keep showing the current state,
+// and on the tock it commits the next state to its outputs. Chips which feed each other can thereby be clocked in any
+// order, as none of them observes the next state of another before the tock.
+type Sequential interface {
+	Tick()
+	Tock()
+}
+
+// Clock drives the sequential chips registered with it through the two phases of every clock cycle, like the clock of
+// the hardware simulator in the book.
+type Clock struct {
+	chips []Sequential
+	// ticked is set between a tick and the following tock
+	ticked bool
+}
+
+// Register connects chips to the clock, they are ticked and tocked in the order they were registered in.
+func (c *Clock) Register(chips ...Sequential) {
+	c.chips = append(c.chips, chips...)
+}
+
+// Tick lets every registered chip compute its next state. Calling Tick again before Tock does nothing.
+func (c *Clock) Tick() {
+	if c.ticked {
+		return
+	}
+	for _, chip := range c.chips {
+		chip.Tick()
+	}
+	c.ticked = true
+}
+
+// Tock lets every registered chip commit the state computed on the preceding tick. Calling Tock without a preceding
+// Tick does nothing.
+func (c *Clock) Tock() {
+	if !c.ticked {
+		return
+	}
+	for _, chip := range c.chips {
+		chip.Tock()
+	}
+	c.ticked = false
+}
+
+// Ticked reports whether the clock is in the middle of a cycle, that is whether it has been ticked but not yet tocked.
+func (c *Clock) Ticked() bool {
+	return c.ticked
+}
+
+// cycle runs a complete clock cycle on chips which are not registered with a Clock
+func cycle(chips ...Sequential) {
+	for _, chip := range chips {
+		chip.Tick()
+	}
+	for _, chip := range chips {
+		chip.Tock()
+	}
+}
@@ -0,0 +1,58 @@
+package chip
+
+import "testing"
+
+func TestClock(t *testing.T) {
+	// Each register is fed the output of the other, which swaps their values on every cycle regardless of the order in
+	// which they are clocked
+	var x, y Register
+	x.Out(Active, WrapUint16(1))
+	y.Out(Active, WrapUint16(2))
+	var clock Clock
+	clock.Register(&x, &y)
+	var assertions = []struct {
+		name string
+		run  func()
+		x, y uint16
+	}{
+		{name: "tick keeps the outputs", run: clock.Tick, x: 1, y: 2},
+		{name: "second tick does nothing", run: clock.Tick, x: 1, y: 2},
+		{name: "tock commits the next state", run: clock.Tock, x: 2, y: 1},
+		{name: "second tock does nothing", run: clock.Tock, x: 2, y: 1},
+		{name: "next cycle swaps again", run: func() { clock.Tick(); clock.Tock() }, x: 1, y: 2},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			x.In(Active, y.Value())
+			y.In(Active, x.Value())
+			assert.run()
+			if actual := x.Value().Uint16(); actual != assert.x {
+				t.Errorf("expected x to be %d but got %d", assert.x, actual)
+			}
+			if actual := y.Value().Uint16(); actual != assert.y {
+				t.Errorf("expected y to be %d but got %d", assert.y, actual)
+			}
+		})
+	}
+}
+
+func TestClock_packed(t *testing.T) {
+	// Like TestClock, with the registers of a PackedCPU
+	var x, y PackedRegister
+	x.Out(Active, 1)
+	y.Out(Active, 2)
+	var clock Clock
+	clock.Register(&x, &y)
+	for _, expected := range [][2]Packed{{2, 1}, {1, 2}, {2, 1}} {
+		x.In(Active, y.Value())
+		y.In(Active, x.Value())
+		clock.Tick()
+		if x.Value() != expected[1] || y.Value() != expected[0] {
+			t.Errorf("expected the tick to keep %d and %d but got %d and %d", expected[1], expected[0], x.Value(), y.Value())
+		}
+		clock.Tock()
+		if x.Value() != expected[0] || y.Value() != expected[1] {
+			t.Errorf("expected %d and %d but got %d and %d", expected[0], expected[1], x.Value(), y.Value())
+		}
+	}
+}
@@ -1,25 +1,77 @@
 package chip
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ROMSize is the number of instructions that can be addressed by the program counter and thereby the largest program
+// that the Computer is able to run.
+const ROMSize = 32768
+
+// RAMSize is the number of words addressable by the A register and thereby the size of the RAM, including the memory maps
+// of the screen and keyboard.
+const RAMSize = 32768
+
+// ErrHalted is returned by Computer.Tick when the program counter has left the program loaded into the ROM and the
+// Computer has not been configured with a fill instruction.
+var ErrHalted = errors.New("computer halted")
+
 // NewComputer creates a new Computer chip with the provided program preloaded into its ROM.
-func NewComputer(rom Memory) Computer {
-	return Computer{
+func NewComputer(rom Memory) *Computer {
+	return NewComputerWithRAM(rom, &RAM{})
+}
+
+// NewComputerWithRAM creates a new Computer chip with the provided program preloaded into its ROM and ram as its data
+// memory, such as a Bus with devices attached to it or the gate-level DataMemory.
+func NewComputerWithRAM(rom Memory, ram Memory) *Computer {
+	c := &Computer{
 		rom: rom,
-		mem: &RAM{},
+		mem: ram,
+	}
+	c.clock.Register(&c.cpu.a, &c.cpu.d, &c.cpu.pc)
+	if mem, ok := ram.(SequentialMemory); ok {
+		c.clock.Register(mem)
 	}
+	return c
 }
 
 type Memory interface {
 	Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word
 }
 
+// Sized is implemented by memories which hold fewer words than their address space allows for, such as a ROM which only
+// contains as many words as the program it was loaded with.
+type Sized interface {
+	Len() int
+}
+
 type ROM [][16]Signal
 
+// NewROM creates a ROM holding the machine code in words, such as a program assembled by the asm package.
+func NewROM(words []uint16) ROM {
+	rom := make(ROM, len(words))
+	for i, w := range words {
+		rom[i] = split16(w)
+	}
+	return rom
+}
+
+// Out reads the instruction stored at the provided address. Addresses beyond the end of the loaded program read as zero.
 func (r ROM) Out(_ Signal, addr [15]Signal, _ ReadonlyWord) *Word {
-	return Wrap(&r[Join15(addr)])
+	idx := Join15(addr)
+	if int(idx) >= len(r) {
+		return NewWord()
+	}
+	return Wrap(&r[idx])
+}
+
+func (r ROM) Len() int {
+	return len(r)
 }
 
 type RAM struct {
-	mem [32768][16]Signal
+	mem [RAMSize][16]Signal
 }
 
 func (b *RAM) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
@@ -35,20 +87,208 @@ type Computer struct {
 	rom Memory
 	cpu CPU
 	mem Memory
+	// clock drives the registers of the CPU, as well as the data memory if it is a SequentialMemory, which are registered
+	// with it by the constructor
+	clock Clock
+	// pending is the output of the CPU to the RAM in the current cycle, which is written on the tock
+	pending output
+	// fill is executed in place of every instruction beyond the end of the program in ROM, if it is nil then the
+	// Computer halts instead
+	fill *[16]Signal
+	// tracer is notified of every executed cycle unless it is nil
+	tracer Tracer
+	// cycle is the cycle to notify tracer of, filled in on the tick and completed on the tock
+	cycle Cycle
+	// cycles counts the instructions executed since the Computer was created
+	cycles uint64
+}
+
+// output holds the outputs of the CPU to the RAM
+type output struct {
+	value *Word
+	write Signal
+	addr  [15]Signal
+}
+
+// Probe holds the values that can be observed on a Computer, see Computer.Probe.
+type Probe struct {
+	A  uint16
+	D  uint16
+	PC uint16
+	// OutM, WriteM and AddressM are the outputs of the CPU to the RAM
+	OutM     uint16
+	WriteM   bool
+	AddressM uint16
 }
 
 func (c *Computer) RAM() Memory {
 	return c.mem
 }
 
-func (c *Computer) Tick(rst Signal) {
-	addr := c.cpu.pc.Out(Inactive, Inactive, rst, NullWord)
+// peek reads the word at addr for the purpose of inspecting the state of the Computer. Only the plain memory of a Bus
+// is read, false is returned for the addresses of any other device as reading them could have side effects. Likewise,
+// only the registers of a memory built from registers, such as a DataMemory, are read and they are read directly.
+func (c *Computer) peek(addr uint16) (uint16, bool) {
+	switch mem := c.mem.(type) {
+	case *Bus:
+		return mem.Peek(addr)
+	case registerMemory:
+		if r := mem.register(addr); r != nil {
+			return r.Value().Uint16(), true
+		}
+		return 0, false
+	default:
+		return c.mem.Out(Inactive, split15(addr), NullWord).Uint16(), true
+	}
+}
+
+// poke writes value to addr for the purpose of restoring the state of the Computer. As with peek, only the plain memory
+// of a Bus and the registers of a memory built from registers are written, false is returned for any other address.
+func (c *Computer) poke(addr uint16, value uint16) bool {
+	switch mem := c.mem.(type) {
+	case *Bus:
+		return mem.Poke(addr, value)
+	case registerMemory:
+		if r := mem.register(addr); r != nil {
+			r.Out(Active, WrapUint16(value))
+			return true
+		}
+		return false
+	default:
+		c.mem.Out(Active, split15(addr), WrapUint16(value))
+		return true
+	}
+}
+
+// Halt configures the Computer to halt once its program counter leaves the program loaded into its ROM, which is also
+// the default behaviour. A halted Computer does not execute anything and every call to Tick returns ErrHalted until the
+// Computer is reset.
+func (c *Computer) Halt() {
+	c.fill = nil
+}
+
+// Fill configures the Computer to execute instr for every address beyond the end of the program loaded into its ROM,
+// as if the remainder of the ROM had been filled with it.
+func (c *Computer) Fill(instr [16]Signal) {
+	c.fill = &instr
+}
+
+// Tick executes a single instruction by running a complete clock cycle, or by completing the current cycle if the
+// Computer has been stopped in the middle of one by HalfTick, in which case rst is ignored. ROMs which do not implement
+// Sized are regarded as filling the entire address space and thereby never cause the Computer to halt.
+func (c *Computer) Tick(rst Signal) error {
+	if c.clock.Ticked() {
+		return c.tock()
+	}
+	if err := c.tick(rst); err != nil {
+		return err
+	}
+	return c.tock()
+}
+
+// HalfTick advances the Computer by half a clock cycle. On the tick the CPU executes the instruction addressed by the
+// program counter, computing its outputs and the next values of its registers, which are committed along with the
+// write to RAM on the tock. Probe shows the Computer in between. The rst pin is only sampled on the tick, which is also
+// where the Computer halts, while errors returned by the Tracer are passed on from the tock.
+func (c *Computer) HalfTick(rst Signal) error {
+	if c.clock.Ticked() {
+		return c.tock()
+	}
+	return c.tick(rst)
+}
+
+// Ticked reports whether the Computer is in the middle of a cycle, that is whether HalfTick has ticked it but not yet
+// tocked it.
+func (c *Computer) Ticked() bool {
+	return c.clock.Ticked()
+}
+
+// Probe returns the values of the registers of the CPU and of its outputs to the RAM. In the middle of a cycle the
+// registers still hold the values they had before the instruction, while the outputs are already those of the
+// instruction. At other times the outputs are those of the most recently executed instruction.
+func (c *Computer) Probe() Probe {
+	p := Probe{
+		A:        c.cpu.a.Value().Uint16(),
+		D:        c.cpu.d.Value().Uint16(),
+		PC:       c.cpu.pc.Value().Uint16(),
+		WriteM:   c.pending.write == Active,
+		AddressM: Join15(c.pending.addr),
+	}
+	if c.pending.value != nil {
+		p.OutM = c.pending.value.Uint16()
+	}
+	return p
+}
+
+// abandon drops the instruction executed on the tick of the current cycle, if any, as if the Computer had not been
+// ticked at all
+func (c *Computer) abandon() {
+	c.clock.ticked = false
+	c.pending = output{}
+}
+
+// tick fetches and executes an instruction, leaving its effects on the registers and the RAM to the tock
+func (c *Computer) tick(rst Signal) error {
+	// A reset starts over from the first instruction, which also resumes a halted Computer
+	addr := And16To1(Not(rst), c.cpu.pc.Value())
 	instr := c.rom.Out(Inactive, addr.Address(), NullWord)
-	areg := c.cpu.a.Out(Inactive, NullWord)
+	if rom, ok := c.rom.(Sized); ok && int(Join15(addr.Address())) >= rom.Len() {
+		if c.fill == nil {
+			return fmt.Errorf("%w: program counter %d is beyond the end of the program of %d instructions", ErrHalted, Join15(addr.Address()), rom.Len())
+		}
+		instr = Wrap(c.fill)
+	}
+	areg := c.cpu.a.Value()
 	imem := c.mem.Out(Inactive, areg.Address(), NullWord)
+	if c.tracer != nil {
+		// The fetched words may alias memory which is overwritten on the tock, so they must be read before then
+		c.cycle = Cycle{
+			Number:      c.cycles,
+			PC:          Join15(addr.Address()),
+			Instruction: instr.Uint16(),
+			M:           imem.Uint16(),
+		}
+	}
 	omem, wmem, maddr := c.cpu.Out(instr, imem, rst)
-	c.mem.Out(wmem, maddr, omem)
-	return
+	c.pending = output{value: omem, write: wmem, addr: maddr}
+	if mem, ok := c.mem.(SequentialMemory); ok {
+		mem.In(wmem, maddr, omem)
+	}
+	c.clock.Tick()
+	return nil
+}
+
+// tock commits the instruction executed on the tick to the registers and the RAM
+func (c *Computer) tock() error {
+	c.clock.Tock()
+	// The word addressed by A has already been read on the tick, reading it again would be noticed by devices
+	if _, ok := c.mem.(SequentialMemory); !ok && c.pending.write == Active {
+		c.mem.Out(c.pending.write, c.pending.addr, c.pending.value)
+	}
+	c.cycles++
+	if c.tracer == nil {
+		return nil
+	}
+	cycle := c.cycle
+	cycle.A = c.cpu.a.Value().Uint16()
+	cycle.D = c.cpu.d.Value().Uint16()
+	if c.pending.write == Active {
+		cycle.Write = true
+		cycle.Address = Join15(c.pending.addr)
+		cycle.Value = c.pending.value.Uint16()
+	}
+	cycle.Jump = c.cpu.jump == Active
+	return c.tracer.Trace(cycle)
+}
+
+// Cycles returns the number of instructions that the Computer has executed.
+func (c *Computer) Cycles() uint64 {
+	return c.cycles
+}
+
+// Trace installs a Tracer which is notified of every cycle executed by the Computer. Passing nil disables tracing.
+func (c *Computer) Trace(t Tracer) {
+	c.tracer = t
 }
 
 // CPU represents the central processing unit of the Computer. It is responsible for executing instructions coming from
@@ -58,36 +298,84 @@ type CPU struct {
 	d   Register
 	alu ALU
 	pc  PC
+	// jump is set when the most recently executed instruction loaded the program counter from the A register
+	jump Signal
+	// write is set when the most recently executed instruction wrote to RAM at the address held by the A register
+	write Signal
+}
+
+// control holds the control pins that a CPU decodes from an instruction, independently of how it represents words
+type control struct {
+	// ainstr is set by A-instructions, which load the instruction itself into the A register
+	ainstr Signal
+	// alu holds the control pins of the ALU, which are all inactive for A-instructions
+	alu ALU
+	// m selects the word read from RAM rather than the A register as the second operand of the ALU
+	m                    Signal
+	loadA, loadD, writeM Signal
+	// jlt, jeq and jgt are the jump bits of the instruction, see control.jump
+	jlt, jeq, jgt Signal
+}
+
+// decode computes the control pins for instr. Every pin but ainstr is taken from a C-instruction and is thereby
+// inactive for A-instructions.
+func decode(instr [16]Signal) control {
+	cinstr := instr[0]
+	bit := func(i int) Signal {
+		return And(cinstr, instr[i])
+	}
+	ainstr := Not(cinstr)
+	return control{
+		ainstr: ainstr,
+		alu: ALU{
+			ZX: bit(4),
+			NX: bit(5),
+			ZY: bit(6),
+			NY: bit(7),
+			F:  bit(8),
+			NO: bit(9),
+		},
+		m: bit(3),
+		// The A register is loaded with the instruction itself by an A-instruction and with the output of the ALU by a
+		// C-instruction which has it as destination
+		loadA:  Or(ainstr, bit(10)),
+		loadD:  bit(11),
+		writeM: bit(12),
+		jlt:    bit(13),
+		jeq:    bit(14),
+		jgt:    bit(15),
+	}
+}
+
+// jump reports whether the program counter is to be loaded from the A register, given the zr and ng outputs of the ALU.
+func (c control) jump(zr, ng Signal) Signal {
+	jgt := And(c.jgt, And(Not(zr), Not(ng)))
+	jeq := And(c.jeq, zr)
+	jge := And(Not(ng), And(c.jgt, c.jeq))
+	jlt := And(c.jlt, ng)
+	jne := And(Not(zr), And(c.jlt, c.jgt))
+	jle := And(And(c.jlt, c.jeq), Or(zr, ng))
+	jmp := And(c.jgt, And(c.jlt, c.jeq))
+	return Or(jgt, Or(jeq, Or(jge, Or(jlt, Or(jne, Or(jle, jmp))))))
 }
 
+// Out computes the outputs of the CPU for instr, with imem as the word read from RAM at the address held by the A
+// register, and sets the inputs of the registers for the next tick. The registers keep their values until the tock.
 func (c *CPU) Out(instr ReadonlyWord, imem ReadonlyWord, rst Signal) (omem *Word, wmem Signal, addr [15]Signal) {
-	a := Mux2Way16(instr.Get(0), instr, c.a.Out(Inactive, NullWord))
-	a = c.a.Out(Not(instr.Get(0)), a)
-	instr = And16To1(instr.Get(0), instr)
-
-	c.alu.ZX = instr.Get(4)
-	c.alu.NX = instr.Get(5)
-	c.alu.ZY = instr.Get(6)
-	c.alu.NY = instr.Get(7)
-	c.alu.F = instr.Get(8)
-	c.alu.NO = instr.Get(9)
-	opa := Mux2Way16(instr.Get(3), a, imem)
-	opb := c.d.Out(Inactive, NullWord)
+	a := c.a.Value()
+	ctl := decode(instr.Copy())
+	c.alu = ctl.alu
+	opa := Mux2Way16(ctl.m, a, imem)
+	opb := c.d.Value()
 	omem, zr, ng := c.alu.Out(opb, opa)
 
-	c.a.Out(instr.Get(10), omem)
-	c.d.Out(instr.Get(11), omem)
-	wmem = instr.Get(12)
+	c.a.In(ctl.loadA, Mux2Way16(ctl.ainstr, omem, instr))
+	c.d.In(ctl.loadD, omem)
+	wmem = ctl.writeM
+	c.write = wmem
 	addr = a.Address()
 
-	jgt := And(instr.Get(15), And(Not(zr), Not(ng)))
-	jeq := And(instr.Get(14), zr)
-	jge := And(Not(ng), And(instr.Get(15), instr.Get(14)))
-	jlt := And(instr.Get(13), ng)
-	jne := And(Not(zr), And(instr.Get(13), instr.Get(15)))
-	jle := And(And(instr.Get(13), instr.Get(14)), Or(zr, ng))
-	jmp := And(instr.Get(15), And(instr.Get(13), instr.Get(14)))
-	inc := Not(Or(jgt, Or(jeq, Or(jge, Or(jlt, Or(jne, Or(jle, jmp)))))))
-	c.pc.Out(Not(inc), inc, rst, a)
+	c.jump = ctl.jump(zr, ng)
+	c.pc.In(c.jump, Not(c.jump), rst, a)
 	return
 }
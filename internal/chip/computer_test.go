@@ -1,6 +1,7 @@
 package chip
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -501,15 +502,16 @@ func TestCPU_Out(t *testing.T) {
 			cpu.d.Out(Active, Wrap(&a.din))
 
 			omem, wmem, addr := cpu.Out(Wrap(&a.instr), Wrap(&a.imem), a.rst)
-			areg := cpu.a.Out(0, NullWord)
+			cycle(&cpu.a, &cpu.d, &cpu.pc)
+			areg := cpu.a.Value()
 			if a.aout != areg.Copy() {
 				t.Errorf("expected aout register to contain %v but found %v", a.aout, areg)
 			}
-			dreg := cpu.d.Out(0, NullWord)
+			dreg := cpu.d.Value()
 			if a.dout != dreg.Copy() {
 				t.Errorf("expected dout register to contain %v but found %v", a.dout, dreg)
 			}
-			pcreg := cpu.pc.Out(0, 0, 0, NullWord).Address()
+			pcreg := cpu.pc.Value().Address()
 			if a.pc != pcreg {
 				t.Errorf("expected pcreg to contain %v but got %v", a.pc, pcreg)
 			}
@@ -575,14 +577,13 @@ func TestComputer_Tick(t *testing.T) {
 	}
 	for _, a := range assertions {
 		t.Run(a.name, func(t *testing.T) {
-			c := Computer{
-				mem: &RAM{},
-			}
-			c.rom = ROM(a.program)
-			pc := c.cpu.pc.Out(Inactive, Inactive, Inactive, NullWord)
+			c := NewComputer(ROM(a.program))
+			pc := c.cpu.pc.Value()
 			for pc.Uint16() < uint16(len(a.program)) {
-				c.Tick(Inactive)
-				pc = c.cpu.pc.Out(Inactive, Inactive, Inactive, NullWord)
+				if err := c.Tick(Inactive); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				pc = c.cpu.pc.Value()
 			}
 			for address, value := range a.mem {
 				if out := c.mem.Out(Inactive, split15(address), NullWord); out.Copy() != value {
@@ -592,3 +593,118 @@ func TestComputer_Tick(t *testing.T) {
 		})
 	}
 }
+
+func TestComputer_Tick_overrun(t *testing.T) {
+	program := ROM{
+		split16(0b0000_0000_0000_0111), // @7
+		split16(0b1110_1100_0001_0000), // D=A
+	}
+	t.Run("halts by default", func(t *testing.T) {
+		c := NewComputer(program)
+		for range len(program) {
+			if err := c.Tick(Inactive); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		for range 2 {
+			if err := c.Tick(Inactive); !errors.Is(err, ErrHalted) {
+				t.Errorf("expected %v but got %v", ErrHalted, err)
+			}
+		}
+		if pc := c.cpu.pc.Value().Uint16(); pc != 2 {
+			t.Errorf("expected halted computer to remain at pc 2 but got %v", pc)
+		}
+		if err := c.Tick(Active); err != nil {
+			t.Errorf("expected reset to resume the computer but got %v", err)
+		}
+	})
+	t.Run("executes the fill instruction beyond the program", func(t *testing.T) {
+		c := NewComputer(program)
+		c.Fill(split16(0b1110_0011_0000_1000)) // M=D
+		for range 4 {
+			if err := c.Tick(Inactive); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if out := c.mem.Out(Inactive, split15(7), NullWord); out.Uint16() != 7 {
+			t.Errorf("expected RAM[7] to contain 7 but got %v", out.Uint16())
+		}
+		c.Halt()
+		if err := c.Tick(Inactive); !errors.Is(err, ErrHalted) {
+			t.Errorf("expected %v but got %v", ErrHalted, err)
+		}
+	})
+}
+
+func TestComputer_HalfTick(t *testing.T) {
+	c := NewComputer(ROM{
+		split16(0b0000_0000_0000_0111), // @7
+		split16(0b1110_1100_0001_0000), // D=A
+		split16(0b0000_0000_0000_0011), // @3
+		split16(0b1110_0111_1100_1000), // M=D+1
+	})
+	// Every half cycle is observed in turn, the registers and RAM only change on the tock while the outputs of the CPU
+	// change on the tick
+	var assertions = []struct {
+		name   string
+		probe  Probe
+		ticked bool
+		m      uint16
+	}{
+		{name: "tick @7", probe: Probe{}, ticked: true},
+		{name: "tock @7", probe: Probe{A: 7, PC: 1}},
+		{name: "tick D=A", probe: Probe{A: 7, PC: 1, OutM: 7, AddressM: 7}, ticked: true},
+		{name: "tock D=A", probe: Probe{A: 7, D: 7, PC: 2, OutM: 7, AddressM: 7}},
+		{name: "tick @3", probe: Probe{A: 7, D: 7, PC: 2, OutM: 7, AddressM: 7}, ticked: true},
+		{name: "tock @3", probe: Probe{A: 3, D: 7, PC: 3, OutM: 7, AddressM: 7}},
+		{name: "tick M=D+1", probe: Probe{A: 3, D: 7, PC: 3, OutM: 8, WriteM: true, AddressM: 3}, ticked: true},
+		{name: "tock M=D+1", probe: Probe{A: 3, D: 7, PC: 4, OutM: 8, WriteM: true, AddressM: 3}, m: 8},
+	}
+	for i, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			if err := c.HalfTick(Inactive); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if probe := c.Probe(); probe != assert.probe {
+				t.Errorf("expected %+v but got %+v", assert.probe, probe)
+			}
+			if c.Ticked() != assert.ticked {
+				t.Errorf("expected ticked to be %v but got %v", assert.ticked, c.Ticked())
+			}
+			if m := c.RAM().Out(Inactive, split15(3), NullWord).Uint16(); m != assert.m {
+				t.Errorf("expected RAM[3] to contain %d but got %d", assert.m, m)
+			}
+			if cycles := c.Cycles(); cycles != uint64((i+1)/2) {
+				t.Errorf("expected %d cycles but got %d", (i+1)/2, cycles)
+			}
+		})
+	}
+	t.Run("tick completes the current cycle", func(t *testing.T) {
+		c := NewComputer(counter)
+		if err := c.HalfTick(Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tick(t, c, 1)
+		if c.Ticked() || c.Cycles() != 1 {
+			t.Errorf("expected a single completed cycle but got %d cycles with ticked %v", c.Cycles(), c.Ticked())
+		}
+	})
+	t.Run("restore abandons the current cycle", func(t *testing.T) {
+		c := NewComputer(counter)
+		tick(t, c, 1)
+		snapshot := c.Snapshot()
+		// Executes M=M+1 and stops halfway through the following @0
+		for range 3 {
+			if err := c.HalfTick(Inactive); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := c.Restore(snapshot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tick(t, c, 1)
+		if m := c.RAM().Out(Inactive, split15(0), NullWord).Uint16(); m != 1 {
+			t.Errorf("expected RAM[0] to contain 1 but got %d", m)
+		}
+	})
+}
@@ -67,6 +67,22 @@ func Or16To1(a Signal, b ReadonlyWord) *Word {
 	return r
 }
 
+// Or8Way is active if any of its 8 inputs is active.
+func Or8Way(in [8]Signal) Signal {
+	return Or(
+		Or(Or(in[0], in[1]), Or(in[2], in[3])),
+		Or(Or(in[4], in[5]), Or(in[6], in[7])),
+	)
+}
+
+// And8Way is active if all of its 8 inputs are active.
+func And8Way(in [8]Signal) Signal {
+	return And(
+		And(And(in[0], in[1]), And(in[2], in[3])),
+		And(And(in[4], in[5]), And(in[6], in[7])),
+	)
+}
+
 func Xor(a, b Signal) Signal {
 	return Or(And(a, Not(b)), And(Not(a), b))
 }
@@ -98,6 +114,20 @@ func Mux2Way1(s Signal, a, b Signal) Signal {
 	return Or(And(Not(s), a), And(s, b))
 }
 
+// Mux4Way1 provides a multiplexer for 4 single bit inputs and a selector consisting of 2 bits.
+func Mux4Way1(s [2]Signal, a, b, c, d Signal) Signal {
+	ab := Mux2Way1(s[1], a, b)
+	cd := Mux2Way1(s[1], c, d)
+	return Mux2Way1(s[0], ab, cd)
+}
+
+// Mux8Way1 provides a multiplexer for 8 single bit inputs and a selector consisting of 3 bits.
+func Mux8Way1(s [3]Signal, a, b, c, d, e, f, g, h Signal) Signal {
+	abcd := Mux4Way1([2]Signal{s[1], s[2]}, a, b, c, d)
+	efgh := Mux4Way1([2]Signal{s[1], s[2]}, e, f, g, h)
+	return Mux2Way1(s[0], abcd, efgh)
+}
+
 // Mux4Way16 provides a multiplexer for 4 inputs and a selector consisting of 2 bytes. Non-zero values on
 // selector bytes are considered as set and only zero is considered unset.
 func Mux4Way16(s [2]Signal, a, b, c, d ReadonlyWord) *Word {
@@ -179,15 +209,23 @@ func DMux8Way1(s [3]Signal, in Signal) (a, b, c, d, e, f, g, h Signal) {
 	return a, b, c, d, e, f, g, h
 }
 
-// DFF represents a data flip-flop capable of holding a single bit of information across CPU cycles.
+// DFF represents a data flip-flop capable of holding a single bit of information across clock cycles. It samples In on
+// the tick of the clock and shows the sampled value on its output from the following tock.
 type DFF struct {
-	In  Signal
-	out Signal
+	In Signal
+	// next is the value sampled on the most recent tick
+	next Signal
+	out  Signal
 }
 
-func (d *DFF) Out(load Signal) Signal {
-	if load == Active {
-		d.out = d.In
-	}
+func (d *DFF) Out() Signal {
 	return d.out
 }
+
+func (d *DFF) Tick() {
+	d.next = d.In
+}
+
+func (d *DFF) Tock() {
+	d.out = d.next
+}
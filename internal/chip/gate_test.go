@@ -917,23 +917,83 @@ func TestDemux8Way(t *testing.T) {
 func TestDFF(t *testing.T) {
 	dff := &DFF{}
 	dff.In = Active
-	if bit := dff.Out(Inactive); bit == Active {
-		t.Errorf("expected dff to be unset before tick")
+	dff.Tick()
+	if bit := dff.Out(); bit == Active {
+		t.Errorf("expected dff to be unset before tock")
 	}
-	if bit := dff.Out(Active); bit == Inactive {
-		t.Errorf("expected dff to be set after tick")
+	dff.Tock()
+	if bit := dff.Out(); bit == Inactive {
+		t.Errorf("expected dff to be set after tock")
 	}
 	dff.In = Inactive
-	if bit := dff.Out(Inactive); bit == Inactive {
+	if bit := dff.Out(); bit == Inactive {
 		t.Errorf("expected dff to be set before tick")
 	}
-	if bit := dff.Out(Active); bit == Active {
-		t.Errorf("expected dff to be unset after tick")
+	dff.Tick()
+	dff.In = Active
+	dff.Tock()
+	if bit := dff.Out(); bit == Active {
+		t.Errorf("expected dff to hold the value sampled on tick but got %v", bit)
+	}
+	dff.Tock()
+	if bit := dff.Out(); bit == Active {
+		t.Errorf("expected dff to be unset after a tock without tick")
+	}
+}
+
+// bits returns the n least significant bits of v as signals, with the most significant bit first
+func bits(v uint, n int) []Signal {
+	s := make([]Signal, n)
+	for i := range n {
+		s[i] = Signal((v >> (n - 1 - i)) & 1)
 	}
-	if bit := dff.Out(Active); bit == Active {
-		t.Errorf("expected dff to be unset after tick")
+	return s
+}
+
+func TestOr8Way(t *testing.T) {
+	for v := range uint(256) {
+		in := [8]Signal(bits(v, 8))
+		var want Signal
+		if v != 0 {
+			want = Active
+		}
+		if r := Or8Way(in); r != want {
+			t.Errorf("expected %v with %v but got %v", want, in, r)
+		}
 	}
-	if bit := dff.Out(Active); bit == Active {
-		t.Errorf("expected dff to be unset after tick")
+}
+
+func TestAnd8Way(t *testing.T) {
+	for v := range uint(256) {
+		in := [8]Signal(bits(v, 8))
+		var want Signal
+		if v == 255 {
+			want = Active
+		}
+		if r := And8Way(in); r != want {
+			t.Errorf("expected %v with %v but got %v", want, in, r)
+		}
+	}
+}
+
+func TestMux4Way1(t *testing.T) {
+	for v := range uint(1 << 6) {
+		in := bits(v, 6)
+		s := [2]Signal(in[:2])
+		want := in[2+int(Join15([15]Signal{13: s[0], 14: s[1]}))]
+		if r := Mux4Way1(s, in[2], in[3], in[4], in[5]); r != want {
+			t.Errorf("expected %v with %v but got %v", want, in, r)
+		}
+	}
+}
+
+func TestMux8Way1(t *testing.T) {
+	for v := range uint(1 << 11) {
+		in := bits(v, 11)
+		s := [3]Signal(in[:3])
+		want := in[3+int(Join15([15]Signal{12: s[0], 13: s[1], 14: s[2]}))]
+		if r := Mux8Way1(s, in[3], in[4], in[5], in[6], in[7], in[8], in[9], in[10]); r != want {
+			t.Errorf("expected %v with %v but got %v", want, in, r)
+		}
 	}
 }
@@ -0,0 +1,133 @@
+package chip
+
+// delta holds the state that a single tick is about to overwrite, which is all that is needed to undo it
+type delta struct {
+	pc uint16
+	a  uint16
+	d  uint16
+	// m is the value held by RAM at the address in the A register, the only address that the tick can write to
+	m     uint16
+	jump  Signal
+	write Signal
+	// wrote is set when the tick wrote to RAM
+	wrote bool
+	// device is set when the address in the A register is mapped to a device other than a Block, see Computer.peek
+	device bool
+}
+
+// History records the ticks executed by a Computer so that they can be undone, allowing execution to be run backwards.
+// Only the state changed by each tick is recorded, which amounts to the registers of the CPU and the one word of RAM that
+// an instruction can write to. The number of ticks remembered is bounded, once the bound is reached the oldest tick is
+// forgotten for every new tick that is recorded.
+//
+// Changes made to the RAM by anything other than the Computer itself, such as the keyboard, are not recorded and thereby
+// not undone. Neither are writes to devices other than the plain memory of a Bus, which are never read back either as
+// reading a device could have side effects.
+type History struct {
+	computer *Computer
+	// deltas is a ring buffer of the most recent ticks, with the oldest at index start
+	deltas []delta
+	start  int
+	len    int
+}
+
+// NewHistory creates a History which remembers up to the provided number of most recent ticks of c.
+func NewHistory(c *Computer, ticks int) *History {
+	return &History{
+		computer: c,
+		deltas:   make([]delta, ticks),
+	}
+}
+
+// Len returns the number of ticks that can currently be undone.
+func (h *History) Len() int {
+	return h.len
+}
+
+// Tick executes a single instruction on the underlying Computer and records it. Errors are passed on from Computer.Tick,
+// ticks which did not execute anything because the Computer halted are not recorded.
+func (h *History) Tick(rst Signal) error {
+	c := h.computer
+	d := delta{
+		pc:    c.cpu.pc.Value().Uint16(),
+		a:     c.cpu.a.Value().Uint16(),
+		d:     c.cpu.d.Value().Uint16(),
+		jump:  c.cpu.jump,
+		write: c.cpu.write,
+	}
+	var plain bool
+	d.m, plain = c.peek(d.a & 0x7FFF)
+	d.device = !plain
+	cycles := c.cycles
+	err := c.Tick(rst)
+	if c.cycles == cycles || len(h.deltas) == 0 {
+		return err
+	}
+	d.wrote = c.cpu.write == Active
+	if h.len == len(h.deltas) {
+		h.deltas[h.start] = d
+		h.start = (h.start + 1) % len(h.deltas)
+	} else {
+		h.deltas[(h.start+h.len)%len(h.deltas)] = d
+		h.len++
+	}
+	return err
+}
+
+// StepBack undoes the most recently recorded tick, leaving the Computer in the state it was in before executing it. False
+// is returned if there is no tick left to undo.
+func (h *History) StepBack() bool {
+	_, ok := h.pop()
+	return ok
+}
+
+// RunBackTo undoes ticks until the program counter holds pc, such that the instruction at pc is the next to be executed.
+// At least one tick is undone. False is returned if pc is not reached before running out of ticks to undo, in which case
+// the Computer is left in the oldest recorded state.
+func (h *History) RunBackTo(pc uint16) bool {
+	for {
+		d, ok := h.pop()
+		if !ok {
+			return false
+		}
+		if d.pc == pc {
+			return true
+		}
+	}
+}
+
+// RunBackToWrite undoes ticks until the most recent instruction that wrote to RAM at addr has been undone, such that the
+// program counter points at the offending instruction. False is returned if no recorded tick wrote to addr, in which case
+// the Computer is left in the oldest recorded state.
+func (h *History) RunBackToWrite(addr uint16) bool {
+	for {
+		d, ok := h.pop()
+		if !ok {
+			return false
+		}
+		if d.wrote && d.a&0x7FFF == addr {
+			return true
+		}
+	}
+}
+
+// pop undoes the most recently recorded tick and returns its delta
+func (h *History) pop() (delta, bool) {
+	if h.len == 0 {
+		return delta{}, false
+	}
+	h.len--
+	d := h.deltas[(h.start+h.len)%len(h.deltas)]
+	c := h.computer
+	c.abandon()
+	if d.wrote && !d.device {
+		c.poke(d.a&0x7FFF, d.m)
+	}
+	c.cpu.a.Out(Active, WrapUint16(d.a))
+	c.cpu.d.Out(Active, WrapUint16(d.d))
+	c.cpu.pc.register.Out(Active, WrapUint16(d.pc))
+	c.cpu.jump = d.jump
+	c.cpu.write = d.write
+	c.cycles--
+	return d, true
+}
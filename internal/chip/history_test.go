@@ -0,0 +1,132 @@
+package chip
+
+import (
+	"reflect"
+	"testing"
+)
+
+// record ticks the Computer behind h n times and returns a snapshot of the state before each tick, followed by the
+// final state
+func record(t *testing.T, c *Computer, h *History, n int) []Snapshot {
+	t.Helper()
+	snapshots := []Snapshot{c.Snapshot()}
+	for range n {
+		if err := h.Tick(Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		snapshots = append(snapshots, c.Snapshot())
+	}
+	return snapshots
+}
+
+func TestHistory_StepBack(t *testing.T) {
+	c := NewComputer(counter)
+	h := NewHistory(c, 16)
+	snapshots := record(t, c, h, 10)
+	for i := len(snapshots) - 2; i >= 0; i-- {
+		if !h.StepBack() {
+			t.Fatalf("expected to step back to tick %d", i)
+		}
+		if !reflect.DeepEqual(snapshots[i], c.Snapshot()) {
+			t.Fatalf("expected state after stepping back to tick %d to match the recorded state", i)
+		}
+	}
+	if h.StepBack() {
+		t.Error("expected stepping back beyond the first tick to fail")
+	}
+	t.Run("stepping back and forward again is deterministic", func(t *testing.T) {
+		replayed := record(t, c, h, 10)
+		if !reflect.DeepEqual(snapshots, replayed) {
+			t.Error("expected replayed states to match the recorded states")
+		}
+	})
+}
+
+func TestHistory_bounded(t *testing.T) {
+	c := NewComputer(counter)
+	h := NewHistory(c, 3)
+	snapshots := record(t, c, h, 10)
+	if h.Len() != 3 {
+		t.Fatalf("expected 3 ticks of history but got %d", h.Len())
+	}
+	for h.StepBack() {
+	}
+	if !reflect.DeepEqual(snapshots[7], c.Snapshot()) {
+		t.Error("expected the oldest remembered state to be the one before the third to last tick")
+	}
+}
+
+func TestHistory_RunBackTo(t *testing.T) {
+	c := NewComputer(counter)
+	h := NewHistory(c, 16)
+	snapshots := record(t, c, h, 10)
+	if !h.RunBackTo(3) {
+		t.Fatal("expected to reach address 3")
+	}
+	if !reflect.DeepEqual(snapshots[7], c.Snapshot()) {
+		t.Errorf("expected state before the most recent execution of address 3")
+	}
+	if h.RunBackTo(100) {
+		t.Error("expected address 100 never to be reached")
+	}
+	if h.Len() != 0 {
+		t.Errorf("expected to run out of history but %d ticks remain", h.Len())
+	}
+}
+
+func TestHistory_RunBackToWrite(t *testing.T) {
+	c := NewComputer(counter)
+	h := NewHistory(c, 16)
+	snapshots := record(t, c, h, 10)
+	if !h.RunBackToWrite(0) {
+		t.Fatal("expected to find a write to address 0")
+	}
+	// The third increment of RAM[0] happens in the tenth tick
+	if !reflect.DeepEqual(snapshots[9], c.Snapshot()) {
+		t.Errorf("expected state before the most recent write to address 0")
+	}
+	if c.Snapshot().RAM[0] != 2 {
+		t.Errorf("expected RAM[0] to hold 2 but got %d", c.Snapshot().RAM[0])
+	}
+	if h.RunBackToWrite(1) {
+		t.Error("expected address 1 never to be written")
+	}
+}
+
+func TestHistory_devices(t *testing.T) {
+	b := NewHackBus(&Keyboard{})
+	var reads, writes int
+	err := b.Attach("device", 24577, 1, Callbacks{
+		OnRead: func(uint16) uint16 {
+			reads++
+			return 1
+		},
+		OnWrite: func(uint16, uint16) { writes++ },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewComputerWithRAM(ROM{
+		split16(24577),                 // @24577
+		split16(0b1110_1111_1100_1000), // M=1
+	}, b)
+	h := NewHistory(c, 16)
+	for range 2 {
+		if err := h.Tick(Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// The computer itself reads the addressed word on every tick, which is the device once A has been loaded
+	if reads != 1 || writes != 1 {
+		t.Fatalf("expected 1 read and 1 write but got %d and %d", reads, writes)
+	}
+	if !h.RunBackToWrite(24577) {
+		t.Fatal("expected to run back to the write to the device")
+	}
+	if reads != 1 || writes != 1 {
+		t.Errorf("expected the history not to access the device but got %d reads and %d writes", reads-1, writes-1)
+	}
+	if pc := c.Snapshot().PC; pc != 1 {
+		t.Errorf("expected PC to be 1 but got %d", pc)
+	}
+}
@@ -1,13 +1,40 @@
 package chip
 
-// Bit represents a digital snack.Signal that has been stored in a 1-bit register.
+// The chips in this file are Sequential: their inputs are set by In, they compute their next state on the tick of the
+// clock and show it on their outputs from the following tock. Out runs a complete clock cycle at once, for chips which
+// are not registered with a Clock, such as the registers of the RAM chips which are clocked on every access.
+
+// Bit represents a digital Signal that has been stored in a 1-bit register.
 type Bit struct {
-	dff DFF
+	dff      DFF
+	load, in Signal
+}
+
+// In sets the inputs of the Bit for the next clock cycle.
+func (b *Bit) In(load Signal, in Signal) {
+	b.load, b.in = load, in
+}
+
+// Tick feeds the DFF with in if load is set and with its own output otherwise, as in the book.
+func (b *Bit) Tick() {
+	b.dff.In = Mux2Way1(b.load, b.dff.Out(), b.in)
+	b.dff.Tick()
+}
+
+func (b *Bit) Tock() {
+	b.dff.Tock()
+}
+
+// Value returns the stored value.
+func (b *Bit) Value() Signal {
+	return b.dff.Out()
 }
 
+// Out stores in if load is set and returns the stored value, running a complete clock cycle.
 func (b *Bit) Out(load Signal, in Signal) Signal {
-	b.dff.In = Mux2Way1(load, b.dff.Out(load), in)
-	return b.dff.Out(load)
+	b.In(load, in)
+	cycle(b)
+	return b.Value()
 }
 
 // Register represents a simple array of 16 Bit coupled together to store a single 16 bit value.
@@ -15,26 +42,76 @@ type Register struct {
 	bits [16]Bit
 }
 
-// Out reads the currently stored 16 bit value
-func (r *Register) Out(load Signal, in ReadonlyWord) *Word {
+// In sets the inputs of the Register for the next clock cycle.
+func (r *Register) In(load Signal, in ReadonlyWord) {
+	for i := range 16 {
+		r.bits[i].In(load, in.Get(i))
+	}
+}
+
+func (r *Register) Tick() {
+	for i := range 16 {
+		r.bits[i].Tick()
+	}
+}
+
+func (r *Register) Tock() {
+	for i := range 16 {
+		r.bits[i].Tock()
+	}
+}
+
+// Value returns the stored 16 bit value.
+func (r *Register) Value() *Word {
 	w := NewWord()
 	for i := range 16 {
-		w.Set(i, r.bits[i].Out(load, in.Get(i)))
+		w.Set(i, r.bits[i].Value())
 	}
 	return w
 }
 
+// Out stores in if load is set and returns the stored 16 bit value, running a complete clock cycle.
+func (r *Register) Out(load Signal, in ReadonlyWord) *Word {
+	r.In(load, in)
+	cycle(r)
+	return r.Value()
+}
+
 // PC provides a chip with the ability to store a single word as well as increment its value and reset it to 0.
 type PC struct {
-	register Register
+	register       Register
+	load, inc, rst Signal
+	in             [16]Signal
+}
+
+// In allows setting of the counters next value by providing a value in the 16-pin parameter `in` and setting the load
+// to an active pin. To increment the stored value the inc pin must only be set. Finally, to reset the value the rst pin
+// must be active. As in the book, rst takes precedence over load which in turn takes precedence over inc.
+func (c *PC) In(load Signal, inc Signal, rst Signal, in ReadonlyWord) {
+	c.load, c.inc, c.rst, c.in = load, inc, rst, in.Copy()
+}
+
+func (c *PC) Tick() {
+	out := c.register.Value()
+	out = Mux2Way16(c.inc, out, Inc16(out))
+	out = Mux2Way16(c.load, out, Wrap(&c.in))
+	out = And16To1(Not(c.rst), out)
+	c.register.In(Active, out)
+	c.register.Tick()
+}
+
+func (c *PC) Tock() {
+	c.register.Tock()
+}
+
+// Value returns the current value of the counter.
+func (c *PC) Value() *Word {
+	return c.register.Value()
 }
 
-// Out allows setting of the counters current value by providing a value in the 16-pin parameter `in` and setting the
-// load to an active pin. To increment the stored value the inc pin must only be set. Finally, to reset the value the rst
-// pin must be active.
+// Out sets the inputs of the counter as described by In and returns its value, running a complete clock cycle.
 func (c *PC) Out(load Signal, inc Signal, rst Signal, in ReadonlyWord) *Word {
-	out := c.register.Out(load, And16To1(Not(inc), in))
-	out = Adder16(out, Wrap(&[16]Signal{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, And(Not(load), inc)}))
-	out = And16To1(Not(And(Not(load), rst)), out)
-	return c.register.Out(Active, out)
+	c.In(load, inc, rst, in)
+	cycle(c)
+	return c.Value()
 }
@@ -122,3 +122,52 @@ func TestCounter_Out(t *testing.T) {
 		}
 	})
 }
+
+func TestBit_sequences(t *testing.T) {
+	// Every sequence of three calls, each given one of the four combinations of load and in, is compared to a model
+	for v := range uint(1 << 6) {
+		seq := bits(v, 6)
+		bit := Bit{}
+		var stored Signal
+		for i := 0; i < len(seq); i += 2 {
+			load, in := seq[i], seq[i+1]
+			if load == Active {
+				stored = in
+			}
+			if r := bit.Out(load, in); r != stored {
+				t.Errorf("expected %v after %v but got %v", stored, seq[:i+2], r)
+			}
+		}
+	}
+}
+
+func TestPC_precedence(t *testing.T) {
+	// Every combination of the control pins is tried on a counter holding 41, with 7 as input
+	for v := range uint(1 << 3) {
+		pins := bits(v, 3)
+		load, inc, rst := pins[0], pins[1], pins[2]
+		var want uint16
+		switch {
+		case rst == Active:
+			want = 0
+		case load == Active:
+			want = 7
+		case inc == Active:
+			want = 42
+		default:
+			want = 41
+		}
+		pc := PC{}
+		pc.Out(Active, Inactive, Inactive, WrapUint16(41))
+		if r := pc.Out(load, inc, rst, WrapUint16(7)).Uint16(); r != want {
+			t.Errorf("expected %d with load %v, inc %v and rst %v but got %d", want, load, inc, rst, r)
+		}
+	}
+	t.Run("wraps around", func(t *testing.T) {
+		pc := PC{}
+		pc.Out(Active, Inactive, Inactive, WrapUint16(0xFFFF))
+		if r := pc.Out(Inactive, Active, Inactive, NullWord).Uint16(); r != 0 {
+			t.Errorf("expected %d but got %d", 0, r)
+		}
+	})
+}
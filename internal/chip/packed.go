@@ -0,0 +1,264 @@
+package chip
+
+import (
+	"fmt"
+)
+
+// Packed is a ReadonlyWord stored in the bits of a uint16, where position 0 is the most significant bit just like in a
+// Word. Unlike a Word it is passed by value, so the gates in this file, which operate on all 16 bits of their inputs at
+// once, never allocate. Every gate is still derived from NotAndPacked, while shifting bits from one position to another
+// only stands in for the wiring between gates.
+type Packed uint16
+
+func (p Packed) Get(position int) Signal {
+	return Signal(p>>(15-position)) & 1
+}
+
+func (p Packed) Copy() [16]Signal {
+	return split16(uint16(p))
+}
+
+// Pack converts any ReadonlyWord into a Packed word.
+func Pack(w ReadonlyWord) Packed {
+	if p, ok := w.(Packed); ok {
+		return p
+	}
+	var p Packed
+	for i := range 16 {
+		p |= Packed(w.Get(i)) << (15 - i)
+	}
+	return p
+}
+
+// Address returns the 15 least significant bits of the word.
+func (p Packed) Address() Packed {
+	return p & 0x7FFF
+}
+
+func NotAndPacked(a, b Packed) Packed {
+	return ^(a & b)
+}
+
+func NotPacked(a Packed) Packed {
+	return NotAndPacked(a, 0xFFFF)
+}
+
+func AndPacked(a, b Packed) Packed {
+	return NotPacked(NotAndPacked(a, b))
+}
+
+func OrPacked(a, b Packed) Packed {
+	return NotAndPacked(NotPacked(a), NotPacked(b))
+}
+
+func XorPacked(a, b Packed) Packed {
+	return OrPacked(AndPacked(a, NotPacked(b)), AndPacked(NotPacked(a), b))
+}
+
+// spread connects s to all 16 bits of a word
+func spread(s Signal) Packed {
+	return -Packed(s & 1)
+}
+
+// AndPackedTo1 ands every bit of b with a.
+func AndPackedTo1(a Signal, b Packed) Packed {
+	return AndPacked(spread(a), b)
+}
+
+// XorPackedTo1 xors every bit of b with a.
+func XorPackedTo1(a Signal, b Packed) Packed {
+	return XorPacked(spread(a), b)
+}
+
+// Mux2Way16Packed selects a if s is inactive and b if it is active.
+func Mux2Way16Packed(s Signal, a, b Packed) Packed {
+	return OrPacked(AndPackedTo1(Not(s), a), AndPackedTo1(s, b))
+}
+
+// Or16WayPacked is active if any bit of in is active.
+func Or16WayPacked(in Packed) Signal {
+	// Folding the word in half four times ors every bit into the least significant one
+	in = OrPacked(in, in>>8)
+	in = OrPacked(in, in>>4)
+	in = OrPacked(in, in>>2)
+	in = OrPacked(in, in>>1)
+	return in.Get(15)
+}
+
+// Adder16Packed adds a and b, ignoring the carry out of the most significant bit. It is a Kogge-Stone prefix adder: every
+// bit generates a carry if both of its inputs are set and propagates an incoming carry if either is, and four fixed
+// stages combine the generate and propagate signals of spans of 1, 2, 4 and 8 bits into the carry into every bit.
+func Adder16Packed(a, b Packed) Packed {
+	propagate := XorPacked(a, b)
+	g, p := AndPacked(a, b), propagate
+	for _, span := range [...]int{1, 2, 4, 8} {
+		// A span generates a carry if its upper half does, or if its upper half propagates the carry of its lower half
+		g = OrPacked(g, AndPacked(p, g<<span))
+		p = AndPacked(p, p<<span)
+	}
+	return XorPacked(propagate, g<<1)
+}
+
+// Inc16Packed adds one to in, wrapping around from the largest value to zero.
+func Inc16Packed(in Packed) Packed {
+	return Adder16Packed(in, 1)
+}
+
+// OutPacked performs the same operation as Out on Packed words.
+func (a *ALU) OutPacked(x, y Packed) (out Packed, zr Signal, ng Signal) {
+	x = AndPackedTo1(Not(a.ZX), x)
+	x = XorPackedTo1(a.NX, x)
+
+	y = AndPackedTo1(Not(a.ZY), y)
+	y = XorPackedTo1(a.NY, y)
+
+	out = Mux2Way16Packed(a.F, AndPacked(x, y), Adder16Packed(x, y))
+	out = XorPackedTo1(a.NO, out)
+	ng = out.Get(0)
+	zr = Not(Or16WayPacked(out))
+	return
+}
+
+// PackedRegister is a Register holding a Packed word, made of 16 DFFs clocked at once. Like Register it is Sequential.
+type PackedRegister struct {
+	load      Signal
+	in        Packed
+	next, out Packed
+}
+
+// In sets the inputs of the register for the next clock cycle.
+func (r *PackedRegister) In(load Signal, in Packed) {
+	r.load, r.in = load, in
+}
+
+func (r *PackedRegister) Tick() {
+	r.next = Mux2Way16Packed(r.load, r.out, r.in)
+}
+
+func (r *PackedRegister) Tock() {
+	r.out = r.next
+}
+
+// Value returns the stored value.
+func (r *PackedRegister) Value() Packed {
+	return r.out
+}
+
+// Out stores in if load is set and returns the stored value, running a complete clock cycle.
+func (r *PackedRegister) Out(load Signal, in Packed) Packed {
+	r.In(load, in)
+	cycle(r)
+	return r.Value()
+}
+
+// PackedPC is a PC holding a Packed word.
+type PackedPC struct {
+	register       PackedRegister
+	load, inc, rst Signal
+	in             Packed
+}
+
+// In behaves like PC.In.
+func (c *PackedPC) In(load Signal, inc Signal, rst Signal, in Packed) {
+	c.load, c.inc, c.rst, c.in = load, inc, rst, in
+}
+
+func (c *PackedPC) Tick() {
+	out := c.register.Value()
+	out = Mux2Way16Packed(c.inc, out, Inc16Packed(out))
+	out = Mux2Way16Packed(c.load, out, c.in)
+	out = AndPackedTo1(Not(c.rst), out)
+	c.register.In(Active, out)
+	c.register.Tick()
+}
+
+func (c *PackedPC) Tock() {
+	c.register.Tock()
+}
+
+// Value returns the current value of the counter.
+func (c *PackedPC) Value() Packed {
+	return c.register.Value()
+}
+
+// Out behaves like PC.Out.
+func (c *PackedPC) Out(load Signal, inc Signal, rst Signal, in Packed) Packed {
+	c.In(load, inc, rst, in)
+	cycle(c)
+	return c.Value()
+}
+
+// PackedCPU is a CPU which operates on Packed words.
+type PackedCPU struct {
+	a, d PackedRegister
+	alu  ALU
+	pc   PackedPC
+}
+
+// Out behaves like CPU.Out, decoding instr in the same way.
+func (c *PackedCPU) Out(instr Packed, imem Packed, rst Signal) (omem Packed, wmem Signal, addr Packed) {
+	a := c.a.Value()
+	ctl := decode(instr.Copy())
+	c.alu = ctl.alu
+	opa := Mux2Way16Packed(ctl.m, a, imem)
+	opb := c.d.Value()
+	omem, zr, ng := c.alu.OutPacked(opb, opa)
+
+	c.a.In(ctl.loadA, Mux2Way16Packed(ctl.ainstr, omem, instr))
+	c.d.In(ctl.loadD, omem)
+	wmem = ctl.writeM
+	addr = a.Address()
+
+	jump := ctl.jump(zr, ng)
+	c.pc.In(jump, Not(jump), rst, a)
+	return
+}
+
+// PackedComputer is a Computer which operates on Packed words, with a ROM holding its program and RAMSize words of RAM.
+// It leaves out the devices, tracing and fill instruction of a Computer and exists to show how much the representation
+// of words costs.
+type PackedComputer struct {
+	rom []Packed
+	ram [RAMSize]Packed
+	cpu PackedCPU
+	// clock drives the registers of the CPU, which are registered with it by the constructor
+	clock  Clock
+	cycles uint64
+}
+
+// NewPackedComputer creates a PackedComputer with the program in rom.
+func NewPackedComputer(rom ROM) *PackedComputer {
+	c := &PackedComputer{rom: make([]Packed, len(rom))}
+	for i := range rom {
+		c.rom[i] = Pack(Wrap(&rom[i]))
+	}
+	c.clock.Register(&c.cpu.a, &c.cpu.d, &c.cpu.pc)
+	return c
+}
+
+// Tick executes a single instruction, see Computer.Tick.
+func (c *PackedComputer) Tick(rst Signal) error {
+	// A reset starts over from the first instruction, which also resumes a halted Computer
+	addr := AndPackedTo1(Not(rst), c.cpu.pc.Value()).Address()
+	if int(addr) >= len(c.rom) {
+		return fmt.Errorf("%w: program counter %d is beyond the end of the program of %d instructions", ErrHalted, addr, len(c.rom))
+	}
+	instr := c.rom[addr]
+	imem := c.ram[c.cpu.a.Value().Address()]
+	omem, wmem, maddr := c.cpu.Out(instr, imem, rst)
+	c.clock.Tick()
+	c.clock.Tock()
+	c.ram[maddr] = Mux2Way16Packed(wmem, c.ram[maddr], omem)
+	c.cycles++
+	return nil
+}
+
+// Peek returns the word stored at addr in the RAM.
+func (c *PackedComputer) Peek(addr uint16) uint16 {
+	return uint16(c.ram[addr&0x7FFF])
+}
+
+// Cycles returns the number of instructions executed.
+func (c *PackedComputer) Cycles() uint64 {
+	return c.cycles
+}
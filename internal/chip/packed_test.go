@@ -0,0 +1,278 @@
+package chip
+
+import (
+	"errors"
+	"testing"
+)
+
+// samples are words that exercise carries across every bit, the sign bit and both extremes
+var samples = []uint16{
+	0, 1, 2, 3, 7, 0x00FF, 0x0F0F, 0x1234, 0x7FFF, 0x8000, 0x8001, 0xAAAA, 0x5555, 0xFF00, 0xFFFE, 0xFFFF,
+}
+
+func TestPack(t *testing.T) {
+	for _, n := range samples {
+		p := Pack(WrapUint16(n))
+		if uint16(p) != n {
+			t.Errorf("expected %d but got %d", n, p)
+		}
+		if p.Copy() != split16(n) {
+			t.Errorf("expected %v but got %v", split16(n), p.Copy())
+		}
+		for i := range 16 {
+			if p.Get(i) != WrapUint16(n).Get(i) {
+				t.Errorf("expected bit %d of %d to be %v but got %v", i, n, WrapUint16(n).Get(i), p.Get(i))
+			}
+		}
+	}
+}
+
+func TestPacked_gates(t *testing.T) {
+	var assertions = []struct {
+		name   string
+		word   func(a, b ReadonlyWord) *Word
+		packed func(a, b Packed) Packed
+	}{
+		{name: "not and", word: NotAnd16, packed: NotAndPacked},
+		{
+			name:   "not",
+			word:   func(a, _ ReadonlyWord) *Word { return Not16(a) },
+			packed: func(a, _ Packed) Packed { return NotPacked(a) },
+		},
+		{name: "and", word: And16, packed: AndPacked},
+		{name: "or", word: Or16, packed: OrPacked},
+		{name: "xor", word: Xor16, packed: XorPacked},
+		{name: "adder", word: Adder16, packed: Adder16Packed},
+		{
+			name:   "inc",
+			word:   func(a, _ ReadonlyWord) *Word { return Inc16(a) },
+			packed: func(a, _ Packed) Packed { return Inc16Packed(a) },
+		},
+		{
+			name:   "and to 1",
+			word:   func(a, b ReadonlyWord) *Word { return And16To1(a.Get(15), b) },
+			packed: func(a, b Packed) Packed { return AndPackedTo1(a.Get(15), b) },
+		},
+		{
+			name:   "xor to 1",
+			word:   func(a, b ReadonlyWord) *Word { return Xor16To1(a.Get(15), b) },
+			packed: func(a, b Packed) Packed { return XorPackedTo1(a.Get(15), b) },
+		},
+		{
+			name:   "mux",
+			word:   func(a, b ReadonlyWord) *Word { return Mux2Way16(a.Get(0), a, b) },
+			packed: func(a, b Packed) Packed { return Mux2Way16Packed(a.Get(0), a, b) },
+		},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			for _, a := range samples {
+				for _, b := range samples {
+					expected := assert.word(WrapUint16(a), WrapUint16(b)).Uint16()
+					if actual := assert.packed(Packed(a), Packed(b)); uint16(actual) != expected {
+						t.Errorf("expected %d but got %d for %d and %d", expected, actual, a, b)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAdder16Packed(t *testing.T) {
+	// Adding every word to a few others covers every carry chain, including the ones that ripple through all 16 bits
+	for x := range 1 << 16 {
+		for _, y := range []uint16{1, 0x7FFF, 0xFFFF} {
+			if actual := Adder16Packed(Packed(x), Packed(y)); uint16(actual) != uint16(x)+y {
+				t.Fatalf("expected %d but got %d", uint16(x)+y, actual)
+			}
+		}
+	}
+}
+
+func TestALU_OutPacked(t *testing.T) {
+	for control := range 1 << 6 {
+		alu := ALU{
+			ZX: Signal(control >> 5 & 1),
+			NX: Signal(control >> 4 & 1),
+			ZY: Signal(control >> 3 & 1),
+			NY: Signal(control >> 2 & 1),
+			F:  Signal(control >> 1 & 1),
+			NO: Signal(control & 1),
+		}
+		for _, x := range samples {
+			for _, y := range samples {
+				expected, ezr, eng := alu.Out(WrapUint16(x), WrapUint16(y))
+				actual, zr, ng := alu.OutPacked(Packed(x), Packed(y))
+				if uint16(actual) != expected.Uint16() || zr != ezr || ng != eng {
+					t.Fatalf(
+						"expected %d (zr %v, ng %v) but got %d (zr %v, ng %v) for control %06b, x %d and y %d",
+						expected.Uint16(), ezr, eng, actual, zr, ng, control, x, y,
+					)
+				}
+			}
+		}
+	}
+}
+
+func TestPackedPC_Out(t *testing.T) {
+	// Every combination of the control pins is tried on a counter holding 41, with 7 as input, like TestPC_precedence
+	for control := range 1 << 3 {
+		load, inc, rst := Signal(control>>2&1), Signal(control>>1&1), Signal(control&1)
+		var pc PC
+		var packed PackedPC
+		pc.Out(Active, Inactive, Inactive, WrapUint16(41))
+		packed.Out(Active, Inactive, Inactive, 41)
+		expected := pc.Out(load, inc, rst, WrapUint16(7)).Uint16()
+		if actual := packed.Out(load, inc, rst, 7); uint16(actual) != expected {
+			t.Errorf("expected %d but got %d for load %v, inc %v and rst %v", expected, actual, load, inc, rst)
+		}
+	}
+}
+
+func TestPackedComputer_Tick(t *testing.T) {
+	multiply := ROM{
+		split16(0b0000000000000001), // @1
+		split16(0b1110101010001000), // M=0
+		split16(0b0000000000000100), // @4
+		split16(0b1110110000010000), // D=A
+		split16(0b0000000000000000), // @0
+		split16(0b1110001100001000), // M=D
+		split16(0b0000000000000001), // @1
+		split16(0b1111110000010000), // D=M
+		split16(0b0000000000000100), // @4
+		split16(0b1110000010010000), // D=D+A
+		split16(0b0000000000000001), // @1
+		split16(0b1110001100001000), // M=D
+		split16(0b0000000000000000), // @0
+		split16(0b1111110000010000), // D=M
+		split16(0b1110001110010000), // D=D-1
+		split16(0b1110001100001000), // M=D
+		split16(0b0000000000000100), // @4
+		split16(0b1110001100000001), // D;JGT
+	}
+	var assertions = []struct {
+		name    string
+		program ROM
+		ticks   int
+	}{
+		{name: "counter", program: counter, ticks: 100},
+		{name: "multiply", program: multiply, ticks: 42},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			c := NewComputer(assert.program)
+			packed := NewPackedComputer(assert.program)
+			for range assert.ticks {
+				if err := c.Tick(Inactive); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := packed.Tick(Inactive); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			for _, addr := range []uint16{0, 1} {
+				expected := c.RAM().Out(Inactive, split15(addr), NullWord).Uint16()
+				if actual := packed.Peek(addr); actual != expected {
+					t.Errorf("expected RAM[%d] to contain %d but got %d", addr, expected, actual)
+				}
+			}
+		})
+	}
+	t.Run("halts beyond the program", func(t *testing.T) {
+		c := NewPackedComputer(counter[:2])
+		for range 2 {
+			if err := c.Tick(Inactive); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := c.Tick(Inactive); !errors.Is(err, ErrHalted) {
+			t.Errorf("expected %v but got %v", ErrHalted, err)
+		}
+		if c.Cycles() != 2 {
+			t.Errorf("expected 2 cycles but got %d", c.Cycles())
+		}
+	})
+}
+
+func TestPacked_allocations(t *testing.T) {
+	alu := ALU{F: Active}
+	c := NewPackedComputer(counter)
+	var assertions = []struct {
+		name string
+		run  func()
+	}{
+		{name: "alu", run: func() { alu.OutPacked(1234, 5678) }},
+		{name: "adder", run: func() { Adder16Packed(1234, 5678) }},
+		{name: "computer", run: func() { _ = c.Tick(Inactive) }},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, assert.run); allocs != 0 {
+				t.Errorf("expected 0 allocations but got %v", allocs)
+			}
+		})
+	}
+}
+
+// The benchmarks store their results in these sinks to keep the compiler from optimising the benchmarked calls away
+var (
+	wordSink   *Word
+	packedSink Packed
+)
+
+// benchmarkOperands returns operands for the benchmarks, which index them by the loop index to vary their inputs
+func benchmarkOperands() (words [256]*Word, packed [256]Packed) {
+	for i := range words {
+		n := uint16(i * 2654435761 >> 16)
+		words[i], packed[i] = WrapUint16(n), Packed(n)
+	}
+	return words, packed
+}
+
+func BenchmarkALU_Out(b *testing.B) {
+	alu := ALU{F: Active}
+	words, packed := benchmarkOperands()
+	b.Run("word", func(b *testing.B) {
+		for i := range b.N {
+			wordSink, _, _ = alu.Out(words[i%256], words[(i+1)%256])
+		}
+	})
+	b.Run("packed", func(b *testing.B) {
+		for i := range b.N {
+			packedSink, _, _ = alu.OutPacked(packed[i%256], packed[(i+1)%256])
+		}
+	})
+}
+
+func BenchmarkAdder16(b *testing.B) {
+	words, packed := benchmarkOperands()
+	b.Run("word", func(b *testing.B) {
+		for i := range b.N {
+			wordSink = Adder16(words[i%256], words[(i+1)%256])
+		}
+	})
+	b.Run("packed", func(b *testing.B) {
+		for i := range b.N {
+			packedSink = Adder16Packed(packed[i%256], packed[(i+1)%256])
+		}
+	})
+}
+
+func BenchmarkComputer_Tick(b *testing.B) {
+	b.Run("word", func(b *testing.B) {
+		c := NewComputer(counter)
+		for range b.N {
+			if err := c.Tick(Inactive); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("packed", func(b *testing.B) {
+		c := NewPackedComputer(counter)
+		for range b.N {
+			if err := c.Tick(Inactive); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,373 @@
+package chip
+
+// The chips in this file build the data memory of the Hack platform out of registers the way the book does, as opposed
+// to RAM which stores its words in an array. Every chip implements SequentialMemory and selects its words by the least
+// significant bits of the address, which lets a chip pass the address on to the chips it is made of unchanged. Like the
+// hardware they simulate, they evaluate every register they contain on every access and clock every register on every
+// cycle, which makes them orders of magnitude slower than RAM.
+
+// SequentialMemory is a Memory built from sequential chips. Like a Register it shows the words it holds on its outputs
+// while a write, set up by In, is committed on the tock of the clock. A Computer registers such a memory with its clock
+// so that writes are committed on the same tock as the registers of the CPU.
+//
+// Out provides the Memory interface for memories which are not registered with a Clock: reads are taken from Value
+// without clocking anything, while writes run a complete clock cycle.
+type SequentialMemory interface {
+	Memory
+	Sequential
+	// In sets the inputs of the memory for the next clock cycle.
+	In(load Signal, addr [15]Signal, in ReadonlyWord)
+	// Value returns the word held at addr.
+	Value(addr [15]Signal) *Word
+}
+
+// registerMemory is implemented by the memories in this file, which allows a Computer to inspect and restore the words
+// they hold without evaluating every register they contain for every word
+type registerMemory interface {
+	// register returns the register holding the word at addr, or nil if addr is not held by a register
+	register(addr uint16) *Register
+}
+
+// out implements Memory.Out for m as described by SequentialMemory
+func out(m SequentialMemory, load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	if load == Active {
+		m.In(load, addr, in)
+		cycle(m)
+	}
+	return m.Value(addr)
+}
+
+// RAM8 is a memory of 8 registers, addressed by the 3 least significant bits of the address.
+type RAM8 struct {
+	registers [8]Register
+}
+
+func (r *RAM8) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	a, b, c, d, e, f, g, h := DMux8Way1([3]Signal{addr[12], addr[13], addr[14]}, load)
+	for i, load := range [8]Signal{a, b, c, d, e, f, g, h} {
+		r.registers[i].In(load, in)
+	}
+}
+
+func (r *RAM8) Tick() {
+	for i := range r.registers {
+		r.registers[i].Tick()
+	}
+}
+
+func (r *RAM8) Tock() {
+	for i := range r.registers {
+		r.registers[i].Tock()
+	}
+}
+
+func (r *RAM8) Value(addr [15]Signal) *Word {
+	return Mux8Way16(
+		[3]Signal{addr[12], addr[13], addr[14]},
+		r.registers[0].Value(),
+		r.registers[1].Value(),
+		r.registers[2].Value(),
+		r.registers[3].Value(),
+		r.registers[4].Value(),
+		r.registers[5].Value(),
+		r.registers[6].Value(),
+		r.registers[7].Value(),
+	)
+}
+
+func (r *RAM8) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(r, load, addr, in)
+}
+
+func (r *RAM8) register(addr uint16) *Register {
+	return &r.registers[addr&7]
+}
+
+// RAM64 is a memory of 64 registers made of 8 RAM8, addressed by the 6 least significant bits of the address.
+type RAM64 struct {
+	rams [8]RAM8
+}
+
+func (r *RAM64) mems() [8]SequentialMemory {
+	return [8]SequentialMemory{
+		&r.rams[0], &r.rams[1], &r.rams[2], &r.rams[3], &r.rams[4], &r.rams[5], &r.rams[6], &r.rams[7],
+	}
+}
+
+func (r *RAM64) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	in8Way(r.mems(), [3]Signal{addr[9], addr[10], addr[11]}, load, addr, in)
+}
+
+func (r *RAM64) Tick() {
+	for i := range r.rams {
+		r.rams[i].Tick()
+	}
+}
+
+func (r *RAM64) Tock() {
+	for i := range r.rams {
+		r.rams[i].Tock()
+	}
+}
+
+func (r *RAM64) Value(addr [15]Signal) *Word {
+	return value8Way(r.mems(), [3]Signal{addr[9], addr[10], addr[11]}, addr)
+}
+
+func (r *RAM64) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(r, load, addr, in)
+}
+
+func (r *RAM64) register(addr uint16) *Register {
+	return r.rams[addr>>3&7].register(addr)
+}
+
+// RAM512 is a memory of 512 registers made of 8 RAM64, addressed by the 9 least significant bits of the address.
+type RAM512 struct {
+	rams [8]RAM64
+}
+
+func (r *RAM512) mems() [8]SequentialMemory {
+	return [8]SequentialMemory{
+		&r.rams[0], &r.rams[1], &r.rams[2], &r.rams[3], &r.rams[4], &r.rams[5], &r.rams[6], &r.rams[7],
+	}
+}
+
+func (r *RAM512) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	in8Way(r.mems(), [3]Signal{addr[6], addr[7], addr[8]}, load, addr, in)
+}
+
+func (r *RAM512) Tick() {
+	for i := range r.rams {
+		r.rams[i].Tick()
+	}
+}
+
+func (r *RAM512) Tock() {
+	for i := range r.rams {
+		r.rams[i].Tock()
+	}
+}
+
+func (r *RAM512) Value(addr [15]Signal) *Word {
+	return value8Way(r.mems(), [3]Signal{addr[6], addr[7], addr[8]}, addr)
+}
+
+func (r *RAM512) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(r, load, addr, in)
+}
+
+func (r *RAM512) register(addr uint16) *Register {
+	return r.rams[addr>>6&7].register(addr)
+}
+
+// RAM4K is a memory of 4096 registers made of 8 RAM512, addressed by the 12 least significant bits of the address.
+type RAM4K struct {
+	rams [8]RAM512
+}
+
+func (r *RAM4K) mems() [8]SequentialMemory {
+	return [8]SequentialMemory{
+		&r.rams[0], &r.rams[1], &r.rams[2], &r.rams[3], &r.rams[4], &r.rams[5], &r.rams[6], &r.rams[7],
+	}
+}
+
+func (r *RAM4K) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	in8Way(r.mems(), [3]Signal{addr[3], addr[4], addr[5]}, load, addr, in)
+}
+
+func (r *RAM4K) Tick() {
+	for i := range r.rams {
+		r.rams[i].Tick()
+	}
+}
+
+func (r *RAM4K) Tock() {
+	for i := range r.rams {
+		r.rams[i].Tock()
+	}
+}
+
+func (r *RAM4K) Value(addr [15]Signal) *Word {
+	return value8Way(r.mems(), [3]Signal{addr[3], addr[4], addr[5]}, addr)
+}
+
+func (r *RAM4K) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(r, load, addr, in)
+}
+
+func (r *RAM4K) register(addr uint16) *Register {
+	return r.rams[addr>>9&7].register(addr)
+}
+
+// RAM16K is a memory of 16384 registers made of 4 RAM4K, addressed by the 14 least significant bits of the address.
+type RAM16K struct {
+	rams [4]RAM4K
+}
+
+func (r *RAM16K) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	a, b, c, d := DMux4Way1([2]Signal{addr[1], addr[2]}, load)
+	for i, load := range [4]Signal{a, b, c, d} {
+		r.rams[i].In(load, addr, in)
+	}
+}
+
+func (r *RAM16K) Tick() {
+	for i := range r.rams {
+		r.rams[i].Tick()
+	}
+}
+
+func (r *RAM16K) Tock() {
+	for i := range r.rams {
+		r.rams[i].Tock()
+	}
+}
+
+func (r *RAM16K) Value(addr [15]Signal) *Word {
+	return Mux4Way16(
+		[2]Signal{addr[1], addr[2]},
+		r.rams[0].Value(addr),
+		r.rams[1].Value(addr),
+		r.rams[2].Value(addr),
+		r.rams[3].Value(addr),
+	)
+}
+
+func (r *RAM16K) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(r, load, addr, in)
+}
+
+func (r *RAM16K) register(addr uint16) *Register {
+	return r.rams[addr>>12&3].register(addr)
+}
+
+// Screen is the memory map of the screen, holding ScreenSize registers made of 2 RAM4K, addressed by the 13 least
+// significant bits of the address.
+type Screen struct {
+	rams [2]RAM4K
+}
+
+func (s *Screen) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	a, b := DMux2Way1(addr[2], load)
+	s.rams[0].In(a, addr, in)
+	s.rams[1].In(b, addr, in)
+}
+
+func (s *Screen) Tick() {
+	s.rams[0].Tick()
+	s.rams[1].Tick()
+}
+
+func (s *Screen) Tock() {
+	s.rams[0].Tock()
+	s.rams[1].Tock()
+}
+
+func (s *Screen) Value(addr [15]Signal) *Word {
+	return Mux2Way16(addr[2], s.rams[0].Value(addr), s.rams[1].Value(addr))
+}
+
+func (s *Screen) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(s, load, addr, in)
+}
+
+func (s *Screen) register(addr uint16) *Register {
+	return s.rams[addr>>12&1].register(addr)
+}
+
+// DataMemory is the complete data memory of the Hack platform: a RAM16K followed by the Screen at ScreenAddress and the
+// Keyboard at KeyboardAddress. Reading any address beyond the keyboard yields zero and writing to it does nothing.
+type DataMemory struct {
+	ram      RAM16K
+	screen   Screen
+	keyboard *Keyboard
+	// kbd is set when the next cycle writes to the keyboard, which is passed on to it on the tock
+	kbd Signal
+}
+
+// NewDataMemory creates a DataMemory which reads the keyboard from keyboard.
+func NewDataMemory(keyboard *Keyboard) *DataMemory {
+	return &DataMemory{keyboard: keyboard}
+}
+
+func (m *DataMemory) In(load Signal, addr [15]Signal, in ReadonlyWord) {
+	// The two most significant bits select between the lower and the upper half of the RAM, the screen and the keyboard
+	ram, upper := DMux2Way1(addr[0], load)
+	screen, keyboard := DMux2Way1(addr[1], upper)
+	m.ram.In(ram, addr, in)
+	m.screen.In(screen, addr, in)
+	m.kbd = And(keyboard, Not(beyondKeyboard(addr)))
+}
+
+func (m *DataMemory) Tick() {
+	m.ram.Tick()
+	m.screen.Tick()
+}
+
+func (m *DataMemory) Tock() {
+	m.ram.Tock()
+	m.screen.Tock()
+	if m.kbd == Active {
+		m.keyboard.Write(0, 0)
+		m.kbd = Inactive
+	}
+}
+
+func (m *DataMemory) Value(addr [15]Signal) *Word {
+	kbd := And16To1(Not(beyondKeyboard(addr)), WrapUint16(m.keyboard.Read(0)))
+	return Mux2Way16(
+		addr[0],
+		m.ram.Value(addr),
+		Mux2Way16(addr[1], m.screen.Value(addr), kbd),
+	)
+}
+
+func (m *DataMemory) Out(load Signal, addr [15]Signal, in ReadonlyWord) *Word {
+	return out(m, load, addr, in)
+}
+
+// register returns nil for the keyboard, which is not held by a register, and for the addresses beyond it
+func (m *DataMemory) register(addr uint16) *Register {
+	switch {
+	case addr < ScreenAddress:
+		return m.ram.register(addr)
+	case addr < KeyboardAddress:
+		return m.screen.register(addr)
+	default:
+		return nil
+	}
+}
+
+// beyondKeyboard is active for the addresses above the screen other than the keyboard, as only a single address is
+// taken by the keyboard
+func beyondKeyboard(addr [15]Signal) Signal {
+	var beyond Signal
+	for _, bit := range addr[2:] {
+		beyond = Or(beyond, bit)
+	}
+	return beyond
+}
+
+// in8Way routes load to one of 8 memories by s, setting their inputs to addr and in
+func in8Way(mems [8]SequentialMemory, s [3]Signal, load Signal, addr [15]Signal, in ReadonlyWord) {
+	a, b, c, d, e, f, g, h := DMux8Way1(s, load)
+	for i, load := range [8]Signal{a, b, c, d, e, f, g, h} {
+		mems[i].In(load, addr, in)
+	}
+}
+
+// value8Way returns the word held at addr by the one of 8 memories selected by s
+func value8Way(mems [8]SequentialMemory, s [3]Signal, addr [15]Signal) *Word {
+	return Mux8Way16(
+		s,
+		mems[0].Value(addr),
+		mems[1].Value(addr),
+		mems[2].Value(addr),
+		mems[3].Value(addr),
+		mems[4].Value(addr),
+		mems[5].Value(addr),
+		mems[6].Value(addr),
+		mems[7].Value(addr),
+	)
+}
@@ -0,0 +1,192 @@
+package chip
+
+import (
+	"testing"
+)
+
+func TestRAM8(t *testing.T) {
+	ram := &RAM8{}
+	for i := range uint16(8) {
+		ram.Out(Active, split15(i), WrapUint16(100+i))
+	}
+	for i := range uint16(8) {
+		if out := ram.Out(Inactive, split15(i), NullWord).Uint16(); out != 100+i {
+			t.Errorf("expected %d but got %d", 100+i, out)
+		}
+	}
+	// Only the 3 least significant bits of the address are used
+	if out := ram.Out(Inactive, split15(8+3), NullWord).Uint16(); out != 103 {
+		t.Errorf("expected %d but got %d", 103, out)
+	}
+}
+
+func TestRAMs(t *testing.T) {
+	var assertions = []struct {
+		name string
+		mem  Memory
+		size uint16
+	}{
+		{name: "RAM64", mem: &RAM64{}, size: 64},
+		{name: "RAM512", mem: &RAM512{}, size: 512},
+		{name: "RAM4K", mem: &RAM4K{}, size: 4096},
+		{name: "RAM16K", mem: &RAM16K{}, size: 16384},
+		{name: "Screen", mem: &Screen{}, size: ScreenSize},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			addrs := []uint16{0, 1, 7, 8, assert.size / 2, assert.size/2 + 9, assert.size - 1}
+			for _, addr := range addrs {
+				if out := assert.mem.Out(Active, split15(addr), WrapUint16(addr^0xA5A5)).Uint16(); out != addr^0xA5A5 {
+					t.Errorf("expected write to return %d but got %d", addr^0xA5A5, out)
+				}
+			}
+			for _, addr := range addrs {
+				if out := assert.mem.Out(Inactive, split15(addr), NullWord).Uint16(); out != addr^0xA5A5 {
+					t.Errorf("expected %d at %d but got %d", addr^0xA5A5, addr, out)
+				}
+			}
+			if out := assert.mem.Out(Inactive, split15(2), NullWord).Uint16(); out != 0 {
+				t.Errorf("expected untouched register to hold 0 but got %d", out)
+			}
+			if out := assert.mem.Out(Inactive, split15(assert.size+1), NullWord).Uint16(); out != 1^0xA5A5 {
+				t.Errorf("expected address to wrap around to %d but got %d", 1^0xA5A5, out)
+			}
+		})
+	}
+}
+
+func TestSequentialMemory(t *testing.T) {
+	var assertions = []struct {
+		name string
+		mem  SequentialMemory
+	}{
+		{name: "RAM8", mem: &RAM8{}},
+		{name: "RAM64", mem: &RAM64{}},
+		{name: "RAM4K", mem: &RAM4K{}},
+		{name: "Screen", mem: &Screen{}},
+		{name: "DataMemory", mem: NewDataMemory(&Keyboard{})},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			var clock Clock
+			clock.Register(assert.mem)
+			addr := split15(5)
+			assert.mem.In(Active, addr, WrapUint16(1234))
+			clock.Tick()
+			if out := assert.mem.Value(addr).Uint16(); out != 0 {
+				t.Errorf("expected the write to wait for the tock but got %d", out)
+			}
+			// Reading through Out does not disturb the pending write
+			if out := assert.mem.Out(Inactive, addr, NullWord).Uint16(); out != 0 {
+				t.Errorf("expected %d but got %d", 0, out)
+			}
+			clock.Tock()
+			if out := assert.mem.Value(addr).Uint16(); out != 1234 {
+				t.Errorf("expected %d but got %d", 1234, out)
+			}
+		})
+	}
+	t.Run("keyboard is written on the tock", func(t *testing.T) {
+		keyboard := &Keyboard{}
+		keyboard.Queue(2)
+		keyboard.Press('A')
+		m := NewDataMemory(keyboard)
+		m.In(Active, split15(KeyboardAddress), NullWord)
+		m.Tick()
+		if keyboard.Queued() != 1 {
+			t.Errorf("expected the code to be queued until the tock")
+		}
+		m.Tock()
+		if keyboard.Queued() != 0 {
+			t.Errorf("expected the code to be acknowledged on the tock")
+		}
+	})
+}
+
+func TestDataMemory(t *testing.T) {
+	keyboard := &Keyboard{}
+	m := NewDataMemory(keyboard)
+	write := func(addr uint16, value uint16) {
+		m.Out(Active, split15(addr), WrapUint16(value))
+	}
+	read := func(addr uint16) uint16 {
+		return m.Out(Inactive, split15(addr), NullWord).Uint16()
+	}
+	write(17, 1234)
+	write(ScreenAddress-1, 4321)
+	write(ScreenAddress, 0xFFFF)
+	write(KeyboardAddress-1, 7)
+	write(KeyboardAddress, 9)
+	write(KeyboardAddress+1, 9)
+	keyboard.Press('K')
+	var assertions = []struct {
+		name string
+		addr uint16
+		want uint16
+	}{
+		{name: "ram", addr: 17, want: 1234},
+		{name: "end of ram", addr: ScreenAddress - 1, want: 4321},
+		{name: "screen", addr: ScreenAddress, want: 0xFFFF},
+		{name: "end of screen", addr: KeyboardAddress - 1, want: 7},
+		{name: "keyboard", addr: KeyboardAddress, want: 'K'},
+		{name: "unmapped", addr: KeyboardAddress + 1, want: 0},
+		{name: "ram is not aliased by the screen", addr: 0, want: 0},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			if actual := read(assert.addr); actual != assert.want {
+				t.Errorf("expected %d but got %d", assert.want, actual)
+			}
+		})
+	}
+}
+
+func TestComputer_dataMemory(t *testing.T) {
+	c := NewComputerWithRAM(counter, NewDataMemory(&Keyboard{}))
+	tick(t, c, 8)
+	if word := c.RAM().Out(Inactive, split15(0), NullWord).Uint16(); word != 2 {
+		t.Errorf("expected the counter to be 2 but got %d", word)
+	}
+	t.Run("writes are committed on the tock", func(t *testing.T) {
+		// @0 is followed by M=M+1
+		tick(t, c, 1)
+		if err := c.HalfTick(Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if word := c.RAM().Out(Inactive, split15(0), NullWord).Uint16(); word != 2 {
+			t.Errorf("expected the counter to be 2 until the tock but got %d", word)
+		}
+		if err := c.HalfTick(Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if word := c.RAM().Out(Inactive, split15(0), NullWord).Uint16(); word != 3 {
+			t.Errorf("expected the counter to be 3 but got %d", word)
+		}
+	})
+	t.Run("snapshots read and write the registers directly", func(t *testing.T) {
+		// Going through the memory for every word would evaluate every register of the memory for each of them, which
+		// would keep this test from finishing in any reasonable time
+		snapshot := c.Snapshot()
+		if snapshot.RAM[0] != 3 {
+			t.Errorf("expected the counter to be 3 but got %d", snapshot.RAM[0])
+		}
+		snapshot.RAM[ScreenAddress+ScreenSize-1] = 42
+		restored := NewComputerWithRAM(counter, NewDataMemory(&Keyboard{}))
+		if err := restored.Restore(snapshot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, addr := range []uint16{0, ScreenAddress + ScreenSize - 1} {
+			if word := restored.RAM().Out(Inactive, split15(addr), NullWord).Uint16(); word != snapshot.RAM[addr] {
+				t.Errorf("expected %d at %d but got %d", snapshot.RAM[addr], addr, word)
+			}
+		}
+	})
+	t.Run("the keyboard is left out of snapshots", func(t *testing.T) {
+		if _, ok := c.peek(KeyboardAddress); ok {
+			t.Error("expected the keyboard not to be read")
+		}
+		if c.poke(KeyboardAddress, 1) {
+			t.Error("expected the keyboard not to be written")
+		}
+	})
+}
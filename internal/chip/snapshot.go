@@ -0,0 +1,189 @@
+package chip
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot.MarshalBinary. It is incremented whenever
+// the format changes in a way that older readers do not understand.
+const SnapshotVersion = 1
+
+// snapshotMagic identifies the binary snapshot format
+var snapshotMagic = [4]byte{'H', 'A', 'C', 'K'}
+
+var (
+	// ErrSnapshotFormat is returned when decoding data that is not a snapshot or a snapshot of an unknown version.
+	ErrSnapshotFormat = errors.New("invalid snapshot")
+	// ErrSnapshotROM is returned by Computer.Restore when a snapshot was taken of a Computer running a different program.
+	ErrSnapshotROM = errors.New("snapshot was taken with a different ROM")
+)
+
+// Hash is the SHA-256 hash of the program loaded into a ROM. It is encoded as a hexadecimal string in text formats.
+type Hash [sha256.Size]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(h) {
+		return fmt.Errorf("%w: hash must be %d hexadecimal digits", ErrSnapshotFormat, 2*len(h))
+	}
+	_, err := hex.Decode(h[:], text)
+	return err
+}
+
+// Snapshot holds the complete state of a Computer, which allows a long-running program to be resumed where it left off.
+// The program itself is not part of the snapshot, only its hash which guards against restoring the state into a Computer
+// running some other program.
+//
+// Snapshots are encoded as JSON by the encoding/json package and in a compact binary format by MarshalBinary. The binary
+// format consists of the magic bytes "HACK" followed by the remaining fields, all in big endian byte order: the version
+// as a uint16, the 32 byte ROM hash, the A, D and PC registers as uint16, the cycle count as a uint64 and finally every
+// word of the RAM as a uint16.
+type Snapshot struct {
+	Version int              `json:"version"`
+	ROM     Hash             `json:"rom"`
+	A       uint16           `json:"a"`
+	D       uint16           `json:"d"`
+	PC      uint16           `json:"pc"`
+	Cycles  uint64           `json:"cycles"`
+	RAM     *[RAMSize]uint16 `json:"ram"`
+}
+
+// snapshotHeader is the fixed-size part of the binary snapshot format
+type snapshotHeader struct {
+	Magic   [4]byte
+	Version uint16
+	ROM     Hash
+	A       uint16
+	D       uint16
+	PC      uint16
+	Cycles  uint64
+}
+
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(binary.Size(snapshotHeader{}) + 2*RAMSize)
+	header := snapshotHeader{
+		Magic:   snapshotMagic,
+		Version: uint16(s.Version),
+		ROM:     s.ROM,
+		A:       s.A,
+		D:       s.D,
+		PC:      s.PC,
+		Cycles:  s.Cycles,
+	}
+	if err := binary.Write(&buf, binary.BigEndian, header); err != nil {
+		return nil, err
+	}
+	ram := s.RAM
+	if ram == nil {
+		ram = &[RAMSize]uint16{}
+	}
+	if err := binary.Write(&buf, binary.BigEndian, ram); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	var header snapshotHeader
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil || header.Magic != snapshotMagic {
+		return fmt.Errorf("%w: missing header", ErrSnapshotFormat)
+	}
+	if header.Version != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrSnapshotFormat, header.Version)
+	}
+	ram := &[RAMSize]uint16{}
+	if err := binary.Read(r, binary.BigEndian, ram); err != nil {
+		return fmt.Errorf("%w: truncated RAM", ErrSnapshotFormat)
+	}
+	if r.Len() > 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrSnapshotFormat, r.Len())
+	}
+	*s = Snapshot{
+		Version: int(header.Version),
+		ROM:     header.ROM,
+		A:       header.A,
+		D:       header.D,
+		PC:      header.PC,
+		Cycles:  header.Cycles,
+		RAM:     ram,
+	}
+	return nil
+}
+
+// Snapshot captures the current state of the Computer. When the RAM of the Computer is a Bus, only the words of the RAM
+// and the screen, or of any other Block, are captured while the addresses of other devices are left as zero. Reading
+// devices could have side effects, such as consuming a received byte, and their state lives outside the Computer. The
+// keyboard of a DataMemory is likewise left as zero.
+func (c *Computer) Snapshot() Snapshot {
+	s := Snapshot{
+		Version: SnapshotVersion,
+		ROM:     c.romHash(),
+		A:       c.cpu.a.Value().Uint16(),
+		D:       c.cpu.d.Value().Uint16(),
+		PC:      c.cpu.pc.Value().Uint16(),
+		Cycles:  c.cycles,
+		RAM:     &[RAMSize]uint16{},
+	}
+	for addr := range RAMSize {
+		s.RAM[addr], _ = c.peek(uint16(addr))
+	}
+	return s
+}
+
+// Restore replaces the state of the Computer with the state captured in s. An error is returned, and the Computer is
+// left untouched, if the snapshot is of an unsupported version or was taken of a Computer running a different program.
+// An instruction that the Computer is in the middle of executing, see Computer.HalfTick, is abandoned. As with
+// Snapshot, only the words of a Bus that are mapped to a Block are restored and devices are left untouched.
+func (c *Computer) Restore(s Snapshot) error {
+	if s.Version != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrSnapshotFormat, s.Version)
+	}
+	if s.RAM == nil {
+		return fmt.Errorf("%w: missing RAM", ErrSnapshotFormat)
+	}
+	if hash := c.romHash(); hash != s.ROM {
+		return fmt.Errorf("%w: expected %v but the computer is running %v", ErrSnapshotROM, s.ROM, hash)
+	}
+	c.abandon()
+	c.cpu.a.Out(Active, WrapUint16(s.A))
+	c.cpu.d.Out(Active, WrapUint16(s.D))
+	c.cpu.pc.register.Out(Active, WrapUint16(s.PC))
+	c.cpu.jump = Inactive
+	c.cpu.write = Inactive
+	c.cycles = s.Cycles
+	for addr, word := range s.RAM {
+		c.poke(uint16(addr), word)
+	}
+	return nil
+}
+
+// romHash hashes the program in ROM. ROMs that do not implement Sized are hashed in their entirety.
+func (c *Computer) romHash() Hash {
+	size := ROMSize
+	if rom, ok := c.rom.(Sized); ok {
+		size = rom.Len()
+	}
+	h := sha256.New()
+	word := make([]byte, 2)
+	for addr := range size {
+		binary.BigEndian.PutUint16(word, c.rom.Out(Inactive, split15(uint16(addr)), NullWord).Uint16())
+		h.Write(word)
+	}
+	var hash Hash
+	h.Sum(hash[:0])
+	return hash
+}
@@ -0,0 +1,134 @@
+package chip
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// counter increments RAM[0] in an infinite loop
+var counter = ROM{
+	split16(0b0000_0000_0000_0000), // @0
+	split16(0b1111_1101_1100_1000), // M=M+1
+	split16(0b0000_0000_0000_0000), // @0
+	split16(0b1110_1010_1000_0111), // 0;JMP
+}
+
+func tick(t *testing.T, c *Computer, n int) {
+	t.Helper()
+	for range n {
+		if err := c.Tick(Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestComputer_Snapshot(t *testing.T) {
+	original := NewComputer(counter)
+	tick(t, original, 9)
+	snapshot := original.Snapshot()
+	if snapshot.PC != 1 || snapshot.A != 0 || snapshot.Cycles != 9 || snapshot.RAM[0] != 2 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+
+	t.Run("restored computer continues where the original left off", func(t *testing.T) {
+		restored := NewComputer(counter)
+		if err := restored.Restore(snapshot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tick(t, original, 7)
+		tick(t, restored, 7)
+		if !reflect.DeepEqual(original.Snapshot(), restored.Snapshot()) {
+			t.Errorf("expected restored computer to match the original")
+		}
+	})
+	t.Run("restoring into a different program fails", func(t *testing.T) {
+		other := NewComputer(counter[:2])
+		if err := other.Restore(snapshot); !errors.Is(err, ErrSnapshotROM) {
+			t.Errorf("expected %v but got %v", ErrSnapshotROM, err)
+		}
+	})
+	t.Run("binary round trip", func(t *testing.T) {
+		bin, err := snapshot.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var decoded Snapshot
+		if err := decoded.UnmarshalBinary(bin); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(snapshot, decoded) {
+			t.Errorf("expected %+v but got %+v", snapshot, decoded)
+		}
+	})
+	t.Run("JSON round trip", func(t *testing.T) {
+		bin, err := json.Marshal(snapshot)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var decoded Snapshot
+		if err := json.Unmarshal(bin, &decoded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(snapshot, decoded) {
+			t.Errorf("expected %+v but got %+v", snapshot, decoded)
+		}
+	})
+}
+
+func TestComputer_Snapshot_devices(t *testing.T) {
+	b := NewHackBus(&Keyboard{})
+	var reads, writes int
+	err := b.Attach("device", 24577, 1, Callbacks{
+		OnRead: func(uint16) uint16 {
+			reads++
+			return 1
+		},
+		OnWrite: func(uint16, uint16) { writes++ },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewComputerWithRAM(counter, b)
+	tick(t, c, 9)
+	reads, writes = 0, 0
+	snapshot := c.Snapshot()
+	if snapshot.RAM[0] != 2 || snapshot.RAM[24577] != 0 {
+		t.Errorf("expected RAM[0] to be 2 and the device to be 0 but got %d and %d", snapshot.RAM[0], snapshot.RAM[24577])
+	}
+	snapshot.RAM[24577] = 5
+	if err := c.Restore(snapshot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reads != 0 || writes != 0 {
+		t.Errorf("expected the device not to be accessed but got %d reads and %d writes", reads, writes)
+	}
+}
+
+func TestSnapshot_UnmarshalBinary(t *testing.T) {
+	valid, err := (&Snapshot{Version: SnapshotVersion}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unsupported := append([]byte{}, valid...)
+	unsupported[5] = SnapshotVersion + 1
+	var assertions = []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "wrong magic", data: append([]byte("KCAH"), valid[4:]...)},
+		{name: "unsupported version", data: unsupported},
+		{name: "truncated", data: valid[:len(valid)-1]},
+		{name: "trailing data", data: append(valid, 0)},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			var s Snapshot
+			if err := s.UnmarshalBinary(assert.data); !errors.Is(err, ErrSnapshotFormat) {
+				t.Errorf("expected %v but got %v", ErrSnapshotFormat, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package chip
+
+// Cycle describes the observable effects of a single instruction executed by a Computer.
+type Cycle struct {
+	// Number is the zero-based sequence number of the cycle, counted from when the Computer was created
+	Number uint64
+	// PC is the address of the executed instruction
+	PC uint16
+	// Instruction is the executed instruction
+	Instruction uint16
+	// A and D hold the contents of the registers after the instruction has been executed
+	A uint16
+	D uint16
+	// M is the value that was read from RAM at the address held by the A register before the instruction was executed
+	M uint16
+	// Write is set when the instruction wrote Value to RAM at Address
+	Write   bool
+	Address uint16
+	Value   uint16
+	// Jump is set when the instruction loaded the program counter rather than incrementing it
+	Jump bool
+}
+
+// Tracer is notified by a Computer of every cycle it executes. An error returned from Trace is passed on to the caller of
+// Computer.Tick.
+type Tracer interface {
+	Trace(c Cycle) error
+}
+
+// TracerFunc adapts an ordinary function to the Tracer interface.
+type TracerFunc func(c Cycle) error
+
+func (f TracerFunc) Trace(c Cycle) error {
+	return f(c)
+}
+
+// Tracers passes every cycle on to each of its tracers in order, stopping at the first error.
+type Tracers []Tracer
+
+func (t Tracers) Trace(c Cycle) error {
+	for _, tracer := range t {
+		if err := tracer.Trace(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
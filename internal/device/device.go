@@ -0,0 +1,28 @@
+package device
+
+// Symbols holds the names under which the assembler knows the registers of the devices, mapped to their addresses. The
+// assembler only predefines them when asked to, see asm.Options.
+var Symbols = map[string]uint16{
+	"TIMER_TICKS":     TimerAddress + TimerTicks,
+	"TIMER_MILLIS":    TimerAddress + TimerMillis,
+	"TIMER_COUNTDOWN": TimerAddress + TimerCountdown,
+	"SERIAL_TX":       SerialAddress + SerialTX,
+	"SERIAL_RX":       SerialAddress + SerialRX,
+	"SERIAL_STATUS":   SerialAddress + SerialStatus,
+	"RANDOM":          RandomAddress + RandomValue,
+	"RANDOM_SEED":     RandomAddress + RandomSeed,
+	"DISK_SECTOR":     DiskAddress + DiskSector,
+	"DISK_COMMAND":    DiskAddress + DiskCommand,
+	"DISK_STATUS":     DiskAddress + DiskStatus,
+	"DISK_BUFFER":     DiskAddress + DiskBuffer,
+	"TONE0_FREQUENCY": ToneAddress + ToneFrequency,
+	"TONE0_VOLUME":    ToneAddress + ToneVolume,
+	"TONE0_DURATION":  ToneAddress + ToneDuration,
+	"TONE1_FREQUENCY": ToneAddress + toneRegisters + ToneFrequency,
+	"TONE1_VOLUME":    ToneAddress + toneRegisters + ToneVolume,
+	"TONE1_DURATION":  ToneAddress + toneRegisters + ToneDuration,
+	"UART_TX":         UARTAddress + UARTTX,
+	"UART_RX":         UARTAddress + UARTRX,
+	"UART_STATUS":     UARTAddress + UARTStatus,
+	"KBD_STATUS":      KeyboardStatusAddress,
+}
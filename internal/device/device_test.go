@@ -0,0 +1,46 @@
+package device
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSymbols checks that every register of the devices is known by its name, at an address within its device
+func TestSymbols(t *testing.T) {
+	var assertions = []struct {
+		name  string
+		start uint16
+		size  int
+		count int
+	}{
+		{name: "TIMER", start: TimerAddress, size: TimerSize, count: 3},
+		{name: "SERIAL", start: SerialAddress, size: SerialSize, count: 3},
+		{name: "RANDOM", start: RandomAddress, size: RandomSize, count: 2},
+		{name: "DISK", start: DiskAddress, size: DiskSize, count: 4},
+		{name: "TONE", start: ToneAddress, size: ToneSize, count: 6},
+		{name: "UART", start: UARTAddress, size: UARTSize, count: 3},
+		{name: "KBD", start: KeyboardStatusAddress, size: KeyboardStatusSize, count: 1},
+	}
+	var total int
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			var count int
+			for symbol, addr := range Symbols {
+				if !strings.HasPrefix(symbol, assert.name) {
+					continue
+				}
+				count++
+				if addr < assert.start || int(addr) >= int(assert.start)+assert.size {
+					t.Errorf("expected %s to be within %d-%d but got %d", symbol, assert.start, int(assert.start)+assert.size-1, addr)
+				}
+			}
+			if count != assert.count {
+				t.Errorf("expected %d symbols but got %d", assert.count, count)
+			}
+			total += count
+		})
+	}
+	if total != len(Symbols) {
+		t.Errorf("expected %d symbols but got %d", len(Symbols), total)
+	}
+}
@@ -0,0 +1,123 @@
+package device
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The addresses of the disk controller and the offsets of its registers, which the assembler knows as DISK_SECTOR,
+// DISK_COMMAND, DISK_STATUS and DISK_BUFFER
+const (
+	DiskAddress = RandomAddress + RandomSize
+	DiskSize    = DiskBuffer + SectorSize
+	DiskSector  = 0
+	DiskCommand = 1
+	DiskStatus  = 2
+	DiskBuffer  = 3
+)
+
+// SectorSize is the number of words in a sector of a Disk, which takes up twice as many bytes in its Storage
+const SectorSize = 256
+
+// The commands of a Disk
+const (
+	// DiskRead reads the sector in DiskSector into the buffer
+	DiskRead = 1
+	// DiskWrite writes the buffer to the sector in DiskSector
+	DiskWrite = 2
+)
+
+// The values of the status register of a Disk
+const (
+	DiskOK     = 0
+	DiskFailed = 1
+)
+
+// Storage holds the sectors of a Disk, such as an *os.File.
+type Storage interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Disk is a Device which stores sectors of 256 words in a Storage, with three registers followed by a buffer:
+//
+//   - DiskSector holds the number of the sector that commands operate on.
+//   - Writing DiskRead or DiskWrite to DiskCommand executes the command, which has completed once the write returns.
+//     Reads yield zero.
+//   - DiskStatus holds DiskOK if the most recent command succeeded and DiskFailed otherwise. Writes are ignored.
+//   - The SectorSize words from DiskBuffer onwards hold the data that is read or written by the commands.
+//
+// Sectors are stored as big endian words at offset sector*SectorSize*2 in the Storage. Sectors beyond the end of the
+// Storage read as zeros.
+type Disk struct {
+	storage Storage
+	sector  uint16
+	status  uint16
+	err     error
+	buffer  [SectorSize]uint16
+	bytes   [SectorSize * 2]byte
+}
+
+// NewDisk creates a Disk which stores its sectors in storage.
+func NewDisk(storage Storage) *Disk {
+	return &Disk{storage: storage}
+}
+
+// Err returns the error of the most recent command, if it failed.
+func (d *Disk) Err() error {
+	return d.err
+}
+
+func (d *Disk) Read(offset uint16) uint16 {
+	switch {
+	case offset == DiskSector:
+		return d.sector
+	case offset == DiskStatus:
+		return d.status
+	case offset >= DiskBuffer:
+		return d.buffer[offset-DiskBuffer]
+	default:
+		return 0
+	}
+}
+
+func (d *Disk) Write(offset uint16, value uint16) {
+	switch {
+	case offset == DiskSector:
+		d.sector = value
+	case offset == DiskCommand:
+		d.err = d.execute(value)
+		d.status = DiskOK
+		if d.err != nil {
+			d.status = DiskFailed
+		}
+	case offset >= DiskBuffer:
+		d.buffer[offset-DiskBuffer] = value
+	}
+}
+
+func (d *Disk) execute(command uint16) error {
+	off := int64(d.sector) * int64(len(d.bytes))
+	switch command {
+	case DiskRead:
+		n, err := d.storage.ReadAt(d.bytes[:], off)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		clear(d.bytes[n:])
+		for i := range d.buffer {
+			d.buffer[i] = binary.BigEndian.Uint16(d.bytes[i*2:])
+		}
+		return nil
+	case DiskWrite:
+		for i, word := range d.buffer {
+			binary.BigEndian.PutUint16(d.bytes[i*2:], word)
+		}
+		_, err := d.storage.WriteAt(d.bytes[:], off)
+		return err
+	default:
+		return fmt.Errorf("unknown disk command %d", command)
+	}
+}
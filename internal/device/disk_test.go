@@ -0,0 +1,99 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// memory is an in-memory Storage which grows as it is written to
+type memory []byte
+
+func (m *memory) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(*m)) {
+		return 0, io.EOF
+	}
+	n := copy(p, (*m)[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memory) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(*m) {
+		*m = append(*m, make([]byte, end-len(*m))...)
+	}
+	return copy((*m)[off:], p), nil
+}
+
+// broken is a Storage which fails every operation
+type broken struct{}
+
+func (broken) ReadAt([]byte, int64) (int, error) {
+	return 0, errors.New("broken")
+}
+
+func (broken) WriteAt([]byte, int64) (int, error) {
+	return 0, errors.New("broken")
+}
+
+func TestDisk(t *testing.T) {
+	storage := &memory{}
+	expect := func(t *testing.T, d *Disk, offset uint16, want uint16) {
+		t.Helper()
+		if actual := d.Read(offset); actual != want {
+			t.Errorf("expected %d but got %d", want, actual)
+		}
+	}
+	t.Run("write", func(t *testing.T) {
+		d := NewDisk(storage)
+		d.Write(DiskSector, 2)
+		d.Write(DiskBuffer, 0x1234)
+		d.Write(DiskBuffer+SectorSize-1, 0xABCD)
+		d.Write(DiskCommand, DiskWrite)
+		expect(t, d, DiskStatus, DiskOK)
+		if len(*storage) != 3*SectorSize*2 {
+			t.Fatalf("expected %d bytes but got %d", 3*SectorSize*2, len(*storage))
+		}
+		sector := (*storage)[2*SectorSize*2:]
+		if sector[0] != 0x12 || sector[1] != 0x34 || sector[len(sector)-2] != 0xAB || sector[len(sector)-1] != 0xCD {
+			t.Errorf("expected big endian words but got %x", sector)
+		}
+	})
+	t.Run("read", func(t *testing.T) {
+		d := NewDisk(storage)
+		d.Write(DiskSector, 2)
+		d.Write(DiskCommand, DiskRead)
+		expect(t, d, DiskStatus, DiskOK)
+		expect(t, d, DiskSector, 2)
+		expect(t, d, DiskBuffer, 0x1234)
+		expect(t, d, DiskBuffer+1, 0)
+		expect(t, d, DiskBuffer+SectorSize-1, 0xABCD)
+	})
+	t.Run("sectors beyond the end read as zeros", func(t *testing.T) {
+		d := NewDisk(storage)
+		d.Write(DiskBuffer, 7)
+		d.Write(DiskSector, 100)
+		d.Write(DiskCommand, DiskRead)
+		expect(t, d, DiskStatus, DiskOK)
+		expect(t, d, DiskBuffer, 0)
+	})
+	t.Run("unknown command", func(t *testing.T) {
+		d := NewDisk(storage)
+		d.Write(DiskCommand, 3)
+		expect(t, d, DiskStatus, DiskFailed)
+		d.Write(DiskCommand, DiskRead)
+		expect(t, d, DiskStatus, DiskOK)
+	})
+	t.Run("storage errors", func(t *testing.T) {
+		d := NewDisk(broken{})
+		for _, command := range []uint16{DiskRead, DiskWrite} {
+			d.Write(DiskCommand, command)
+			expect(t, d, DiskStatus, DiskFailed)
+			if d.Err() == nil {
+				t.Error("expected an error but got nil")
+			}
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package device
+
+import (
+	"github.com/crookdc/nand2tetris/internal/chip"
+)
+
+// The address of the status register of a keyboard in queued mode, which the assembler knows as KBD_STATUS
+const (
+	KeyboardStatusAddress = UARTAddress + UARTSize
+	KeyboardStatusSize    = 1
+)
+
+// KeyboardStatus is a read-only Device holding the number of codes queued by a chip.Keyboard in queued mode, which is
+// non-zero whenever the keyboard memory map holds a code that has yet to be acknowledged.
+type KeyboardStatus struct {
+	Keyboard *chip.Keyboard
+}
+
+func (k KeyboardStatus) Read(uint16) uint16 {
+	return uint16(k.Keyboard.Queued())
+}
+
+func (k KeyboardStatus) Write(uint16, uint16) {}
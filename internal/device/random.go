@@ -0,0 +1,44 @@
+package device
+
+import (
+	"math/rand/v2"
+)
+
+// The addresses of the random number generator and the offsets of its registers, which the assembler knows as RANDOM and
+// RANDOM_SEED
+const (
+	RandomAddress = SerialAddress + SerialSize
+	RandomSize    = 2
+	RandomValue   = 0
+	RandomSeed    = 1
+)
+
+// Random is a Device which generates pseudo-random numbers, with two registers:
+//
+//   - RandomValue yields a new 16-bit number on every read. Writes are ignored.
+//   - Writing to RandomSeed restarts the sequence of numbers from the written seed. Reads yield zero.
+//
+// The sequence of numbers is fully determined by the seed and the number of reads, and since the computer reads the word
+// addressed by the A register once per instruction, every instruction executed while A holds RANDOM advances the
+// sequence by exactly one number. A program executed from the same seed therefore always sees the same numbers.
+type Random struct {
+	pcg *rand.PCG
+}
+
+// NewRandom creates a Random generator starting out from seed.
+func NewRandom(seed uint64) *Random {
+	return &Random{pcg: rand.NewPCG(seed, 0)}
+}
+
+func (r *Random) Read(offset uint16) uint16 {
+	if offset != RandomValue {
+		return 0
+	}
+	return uint16(r.pcg.Uint64() >> 48)
+}
+
+func (r *Random) Write(offset uint16, value uint16) {
+	if offset == RandomSeed {
+		r.pcg.Seed(uint64(value), 0)
+	}
+}
@@ -0,0 +1,76 @@
+package device
+
+import (
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"slices"
+	"testing"
+)
+
+// sequence reads n numbers from r
+func sequence(r *Random, n int) []uint16 {
+	s := make([]uint16, n)
+	for i := range s {
+		s[i] = r.Read(RandomValue)
+	}
+	return s
+}
+
+func TestRandom(t *testing.T) {
+	t.Run("same seed gives the same sequence", func(t *testing.T) {
+		a, b := sequence(NewRandom(42), 16), sequence(NewRandom(42), 16)
+		if !slices.Equal(a, b) {
+			t.Errorf("expected %v but got %v", a, b)
+		}
+	})
+	t.Run("different seeds give different sequences", func(t *testing.T) {
+		a, b := sequence(NewRandom(1), 16), sequence(NewRandom(2), 16)
+		if slices.Equal(a, b) {
+			t.Errorf("expected different sequences but got %v twice", a)
+		}
+	})
+	t.Run("every read gives a new number", func(t *testing.T) {
+		s := sequence(NewRandom(7), 16)
+		slices.Sort(s)
+		if len(slices.Compact(s)) < 15 {
+			t.Errorf("expected mostly distinct numbers but got %v", s)
+		}
+	})
+	t.Run("guest seeding restarts the sequence", func(t *testing.T) {
+		r := NewRandom(99)
+		r.Write(RandomSeed, 5)
+		first := sequence(r, 8)
+		sequence(r, 3)
+		r.Write(RandomSeed, 5)
+		if again := sequence(r, 8); !slices.Equal(first, again) {
+			t.Errorf("expected %v but got %v", first, again)
+		}
+		if seed := r.Read(RandomSeed); seed != 0 {
+			t.Errorf("expected %d but got %d", 0, seed)
+		}
+	})
+}
+
+// TestRandom_computer checks that an instruction reading RANDOM draws exactly one number from the generator
+func TestRandom_computer(t *testing.T) {
+	r := NewRandom(3)
+	bus := chip.NewHackBus(&chip.Keyboard{})
+	if err := bus.Attach("random", RandomAddress, RandomSize, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := chip.NewComputerWithRAM(chip.NewROM([]uint16{
+		RandomAddress + RandomValue, // @RANDOM
+		0b1111_1100_0001_0000,       // D=M
+	}), bus)
+	for range 2 {
+		if err := c.Tick(chip.Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	expected := sequence(NewRandom(3), 2)
+	if d := c.Probe().D; d != expected[0] {
+		t.Errorf("expected %d but got %d", expected[0], d)
+	}
+	if next := r.Read(RandomValue); next != expected[1] {
+		t.Errorf("expected the generator to have advanced once to %d but got %d", expected[1], next)
+	}
+}
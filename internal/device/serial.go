@@ -0,0 +1,142 @@
+package device
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// The addresses of the serial console and the offsets of its registers, which the assembler knows as SERIAL_TX, SERIAL_RX
+// and SERIAL_STATUS
+const (
+	SerialAddress = TimerAddress + TimerSize
+	SerialSize    = 3
+	SerialTX      = 0
+	SerialRX      = 1
+	SerialStatus  = 2
+)
+
+// The bits of the status register of the serial console
+const (
+	// SerialReceived is set while SerialRX holds a character that has not been acknowledged
+	SerialReceived = 1 << iota
+	// SerialSendable is set while characters written to SerialTX are sent, which is for as long as the console has not
+	// failed to write
+	SerialSendable
+)
+
+// newline is the Hack character code of the newline, which is sent and received as '\n'
+const newline = 128
+
+// receiveBuffer is the number of characters read ahead of the computer
+const receiveBuffer = 256
+
+// Serial is a Device for text input and output with three registers:
+//
+//   - Writing a character to SerialTX sends it. Reads yield zero.
+//   - SerialRX holds the oldest received character that has not been acknowledged, or zero if there is none. Writing any
+//     value to it acknowledges the character, making room for the next one.
+//   - SerialStatus holds the SerialReceived and SerialSendable bits. Writes are ignored.
+//
+// Characters are Hack character codes, which match the Unicode code points of the characters they represent except for
+// the newline, which is translated to and from '\n'.
+type Serial struct {
+	w        io.Writer
+	err      error
+	received chan uint16
+	// current is the character held by SerialRX, which is valid if ready is set
+	current uint16
+	ready   bool
+}
+
+// NewSerial creates a Serial console which sends characters to w and receives them from r. Either can be nil, in which
+// case nothing is sent or received. Reading from r happens on a goroutine of its own so that the computer never waits on
+// input, and it stops once r returns an error.
+func NewSerial(r io.Reader, w io.Writer) *Serial {
+	s := &Serial{w: w, received: make(chan uint16, receiveBuffer)}
+	if r == nil {
+		close(s.received)
+	} else {
+		go s.receive(bufio.NewReader(r))
+	}
+	return s
+}
+
+// Err returns the error that stopped characters from being sent, if any.
+func (s *Serial) Err() error {
+	return s.err
+}
+
+func (s *Serial) Read(offset uint16) uint16 {
+	switch offset {
+	case SerialRX:
+		s.fetch()
+		return s.current
+	case SerialStatus:
+		s.fetch()
+		var status uint16
+		if s.ready {
+			status |= SerialReceived
+		}
+		if s.w != nil && s.err == nil {
+			status |= SerialSendable
+		}
+		return status
+	default:
+		return 0
+	}
+}
+
+func (s *Serial) Write(offset uint16, value uint16) {
+	switch offset {
+	case SerialTX:
+		s.send(value)
+	case SerialRX:
+		s.current = 0
+		s.ready = false
+	}
+}
+
+// fetch moves the next received character into SerialRX unless it still holds one
+func (s *Serial) fetch() {
+	if s.ready {
+		return
+	}
+	select {
+	case c, ok := <-s.received:
+		if ok {
+			s.current = c
+			s.ready = true
+		}
+	default:
+	}
+}
+
+func (s *Serial) send(c uint16) {
+	if s.w == nil || s.err != nil {
+		return
+	}
+	r := rune(c)
+	if c == newline {
+		r = '\n'
+	}
+	_, s.err = s.w.Write(utf8.AppendRune(nil, r))
+}
+
+func (s *Serial) receive(r *bufio.Reader) {
+	defer close(s.received)
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return
+		}
+		switch {
+		case c == '\n':
+			s.received <- newline
+		case c > 0xFFFF:
+			// Characters beyond 16 bits cannot be represented
+		default:
+			s.received <- uint16(c)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package device
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// await waits for the goroutine reading the input of the serial console to deliver a character
+func await(t *testing.T, s *Serial) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Read(SerialStatus)&SerialReceived == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// receive reads and acknowledges the next character received by the serial console
+func receive(t *testing.T, s *Serial) uint16 {
+	t.Helper()
+	await(t, s)
+	c := s.Read(SerialRX)
+	s.Write(SerialRX, 0)
+	return c
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func TestSerial(t *testing.T) {
+	t.Run("send", func(t *testing.T) {
+		var out strings.Builder
+		s := NewSerial(nil, &out)
+		for _, c := range []uint16{'h', 'é', newline} {
+			s.Write(SerialTX, c)
+		}
+		if out.String() != "hé\n" {
+			t.Errorf("expected %q but got %q", "hé\n", out.String())
+		}
+		if status := s.Read(SerialStatus); status != SerialSendable {
+			t.Errorf("expected status %d but got %d", SerialSendable, status)
+		}
+	})
+	t.Run("receive", func(t *testing.T) {
+		s := NewSerial(strings.NewReader("hé\n"), nil)
+		for _, want := range []uint16{'h', 'é', newline} {
+			if c := receive(t, s); c != want {
+				t.Errorf("expected %d but got %d", want, c)
+			}
+		}
+		if status := s.Read(SerialStatus); status != 0 {
+			t.Errorf("expected status 0 but got %d", status)
+		}
+	})
+	t.Run("received character is held until acknowledged", func(t *testing.T) {
+		s := NewSerial(strings.NewReader("ab"), nil)
+		await(t, s)
+		// Reading repeatedly, as the computer does while A holds the address, must not skip any characters
+		for range 3 {
+			if c := s.Read(SerialRX); c != 'a' {
+				t.Errorf("expected %d but got %d", 'a', c)
+			}
+		}
+		s.Write(SerialRX, 0)
+		if c := receive(t, s); c != 'b' {
+			t.Errorf("expected %d but got %d", 'b', c)
+		}
+	})
+	t.Run("failed writes stop sending", func(t *testing.T) {
+		s := NewSerial(nil, failingWriter{})
+		s.Write(SerialTX, 'x')
+		if s.Err() == nil {
+			t.Error("expected an error but got nil")
+		}
+		if status := s.Read(SerialStatus); status&SerialSendable != 0 {
+			t.Errorf("expected status without %d but got %d", SerialSendable, status)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+// Package device implements peripherals that can be attached to the memory bus of a Hack computer, in the otherwise
+// unused address space above the keyboard.
+package device
+
+import (
+	"time"
+)
+
+// The addresses of the timer and the offsets of its registers, which the assembler knows as TIMER_TICKS, TIMER_MILLIS and
+// TIMER_COUNTDOWN
+const (
+	TimerAddress   = 24577
+	TimerSize      = 3
+	TimerTicks     = 0
+	TimerMillis    = 1
+	TimerCountdown = 2
+)
+
+// Timer is a Device with three registers:
+//
+//   - TimerTicks holds the number of instructions executed by the computer, wrapping around at 16 bits. Writes are
+//     ignored.
+//   - TimerMillis holds the number of milliseconds passed since the timer was created, wrapping around at 16 bits. Writes
+//     are ignored.
+//   - TimerCountdown holds the number of milliseconds left until zero, counting down from the value last written to it.
+//     Writing zero stops the countdown.
+type Timer struct {
+	cycles func() uint64
+	now    func() time.Time
+	start  time.Time
+	// countdown is the value last written to the countdown register, at the time set
+	countdown uint16
+	set       time.Time
+}
+
+// NewTimer creates a Timer that counts the instructions reported by cycles, typically chip.Computer.Cycles, and tells the
+// time by now, typically time.Now.
+func NewTimer(cycles func() uint64, now func() time.Time) *Timer {
+	return &Timer{cycles: cycles, now: now, start: now()}
+}
+
+func (t *Timer) Read(offset uint16) uint16 {
+	switch offset {
+	case TimerTicks:
+		return uint16(t.cycles())
+	case TimerMillis:
+		return uint16(t.now().Sub(t.start).Milliseconds())
+	case TimerCountdown:
+		elapsed := t.now().Sub(t.set).Milliseconds()
+		if elapsed >= int64(t.countdown) {
+			return 0
+		}
+		return t.countdown - uint16(elapsed)
+	default:
+		return 0
+	}
+}
+
+func (t *Timer) Write(offset uint16, value uint16) {
+	if offset == TimerCountdown {
+		t.countdown = value
+		t.set = t.now()
+	}
+}
@@ -0,0 +1,67 @@
+package device
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(ms int) {
+	c.t = c.t.Add(time.Duration(ms) * time.Millisecond)
+}
+
+func TestTimer(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_000_000, 0)}
+	var cycles uint64
+	timer := NewTimer(func() uint64 { return cycles }, clock.now)
+
+	expect := func(t *testing.T, offset uint16, want uint16) {
+		t.Helper()
+		if actual := timer.Read(offset); actual != want {
+			t.Errorf("expected %d but got %d", want, actual)
+		}
+	}
+	t.Run("ticks wrap around at 16 bits", func(t *testing.T) {
+		cycles = 65_536 + 12
+		expect(t, TimerTicks, 12)
+	})
+	t.Run("milliseconds since creation", func(t *testing.T) {
+		clock.advance(1500)
+		expect(t, TimerMillis, 1500)
+		clock.advance(65_536)
+		expect(t, TimerMillis, 1500)
+	})
+	t.Run("counters are read-only", func(t *testing.T) {
+		timer.Write(TimerTicks, 1)
+		timer.Write(TimerMillis, 1)
+		expect(t, TimerTicks, 12)
+		expect(t, TimerMillis, 1500)
+	})
+	t.Run("countdown is idle until written", func(t *testing.T) {
+		expect(t, TimerCountdown, 0)
+	})
+	t.Run("countdown counts down to zero", func(t *testing.T) {
+		timer.Write(TimerCountdown, 100)
+		expect(t, TimerCountdown, 100)
+		clock.advance(40)
+		expect(t, TimerCountdown, 60)
+		clock.advance(60)
+		expect(t, TimerCountdown, 0)
+		clock.advance(1000)
+		expect(t, TimerCountdown, 0)
+	})
+	t.Run("countdown restarts when written", func(t *testing.T) {
+		timer.Write(TimerCountdown, 100)
+		clock.advance(10)
+		timer.Write(TimerCountdown, 50)
+		expect(t, TimerCountdown, 50)
+	})
+}
@@ -0,0 +1,122 @@
+package device
+
+import (
+	"sync"
+)
+
+// The addresses of the tone generator and the offsets of the registers of each of its channels, which the assembler knows
+// as TONE0_FREQUENCY, TONE0_VOLUME and TONE0_DURATION for the first channel and TONE1_* for the second
+const (
+	ToneAddress   = DiskAddress + DiskSize
+	ToneChannels  = 2
+	ToneSize      = ToneChannels * toneRegisters
+	ToneFrequency = 0
+	ToneVolume    = 1
+	ToneDuration  = 2
+	toneRegisters = 3
+)
+
+// SampleRate is the number of samples per second rendered by a Tone
+const SampleRate = 22050
+
+// The loudest a channel can be, which leaves room for all channels to play at full volume without clipping
+const (
+	maxVolume    = 255
+	maxAmplitude = 32767 / ToneChannels
+)
+
+// Tone is a Device which generates square waves on ToneChannels channels. Each channel has three registers, with the
+// registers of channel c starting at offset 3*c:
+//
+//   - ToneFrequency holds the pitch of the tone in Hz. Zero silences the channel.
+//   - ToneVolume holds the loudness of the tone from 0 to 255, where values above 255 are as loud as 255. The volume of
+//     every channel starts out at 255.
+//   - Writing a number of milliseconds to ToneDuration plays the tone for that long, replacing whatever the channel was
+//     playing. It then holds the number of milliseconds left to play, which is zero once the tone has ended.
+//
+// Time passes for a Tone as samples are rendered, not as the computer executes instructions, so a tone plays for as long
+// as it takes whatever consumes the samples to do so. Render may be called from any goroutine.
+type Tone struct {
+	mu       sync.Mutex
+	channels [ToneChannels]channel
+}
+
+// channel is the state of a single channel of a Tone
+type channel struct {
+	frequency uint16
+	volume    uint16
+	// remaining is the number of samples left to play
+	remaining int
+	// phase is the position within the current period of the square wave, from 0 up to 1
+	phase float64
+}
+
+func NewTone() *Tone {
+	t := &Tone{}
+	for i := range t.channels {
+		t.channels[i].volume = maxVolume
+	}
+	return t
+}
+
+func (t *Tone) Read(offset uint16) uint16 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := &t.channels[offset/toneRegisters]
+	switch offset % toneRegisters {
+	case ToneFrequency:
+		return c.frequency
+	case ToneVolume:
+		return c.volume
+	default:
+		// Rounding up keeps the duration from reading zero before the last sample has been rendered
+		return uint16((c.remaining*1000 + SampleRate - 1) / SampleRate)
+	}
+}
+
+func (t *Tone) Write(offset uint16, value uint16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := &t.channels[offset/toneRegisters]
+	switch offset % toneRegisters {
+	case ToneFrequency:
+		c.frequency = value
+	case ToneVolume:
+		c.volume = min(value, maxVolume)
+	default:
+		c.remaining = int(value) * SampleRate / 1000
+		c.phase = 0
+	}
+}
+
+// Render fills samples with the next len(samples) samples of mono signed 16-bit audio at SampleRate.
+func (t *Tone) Render(samples []int16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(samples)
+	for i := range t.channels {
+		t.channels[i].render(samples)
+	}
+}
+
+// render adds the samples of the channel to samples
+func (c *channel) render(samples []int16) {
+	n := min(len(samples), c.remaining)
+	c.remaining -= n
+	if c.frequency == 0 || c.volume == 0 {
+		return
+	}
+	amplitude := int16(maxAmplitude * int(c.volume) / maxVolume)
+	step := float64(c.frequency) / SampleRate
+	for i := range n {
+		if c.phase < 0.5 {
+			samples[i] += amplitude
+		} else {
+			samples[i] -= amplitude
+		}
+		c.phase += step
+		if c.phase >= 1 {
+			c.phase -= float64(int(c.phase))
+		}
+	}
+}
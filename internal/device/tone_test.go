@@ -0,0 +1,92 @@
+package device
+
+import (
+	"testing"
+)
+
+// crossings counts the number of times that samples change sign
+func crossings(samples []int16) int {
+	n := 0
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1] < 0) != (samples[i] < 0) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestTone(t *testing.T) {
+	t.Run("silent until played", func(t *testing.T) {
+		tone := NewTone()
+		tone.Write(ToneFrequency, 440)
+		samples := make([]int16, 100)
+		tone.Render(samples)
+		for _, s := range samples {
+			if s != 0 {
+				t.Fatalf("expected silence but got %v", samples)
+			}
+		}
+	})
+	t.Run("square wave at the frequency", func(t *testing.T) {
+		tone := NewTone()
+		tone.Write(ToneFrequency, 441)
+		tone.Write(ToneDuration, 1000)
+		samples := make([]int16, SampleRate)
+		tone.Render(samples)
+		// A square wave changes sign twice per period
+		if n := crossings(samples); n < 2*441-2 || n > 2*441 {
+			t.Errorf("expected about %d sign changes but got %d", 2*441, n)
+		}
+		if samples[0] != maxAmplitude {
+			t.Errorf("expected %d but got %d", maxAmplitude, samples[0])
+		}
+	})
+	t.Run("duration counts down as samples are rendered", func(t *testing.T) {
+		tone := NewTone()
+		tone.Write(ToneFrequency, 100)
+		tone.Write(ToneDuration, 200)
+		if d := tone.Read(ToneDuration); d != 200 {
+			t.Errorf("expected %d but got %d", 200, d)
+		}
+		samples := make([]int16, SampleRate/10)
+		tone.Render(samples)
+		if d := tone.Read(ToneDuration); d != 100 {
+			t.Errorf("expected %d but got %d", 100, d)
+		}
+		tone.Render(samples)
+		if d := tone.Read(ToneDuration); d != 0 {
+			t.Errorf("expected %d but got %d", 0, d)
+		}
+		tone.Render(samples)
+		if samples[0] != 0 {
+			t.Errorf("expected silence after the tone but got %d", samples[0])
+		}
+	})
+	t.Run("volume", func(t *testing.T) {
+		tone := NewTone()
+		tone.Write(ToneFrequency, 100)
+		tone.Write(ToneVolume, 1000)
+		if v := tone.Read(ToneVolume); v != maxVolume {
+			t.Errorf("expected %d but got %d", maxVolume, v)
+		}
+		tone.Write(ToneVolume, 51)
+		tone.Write(ToneDuration, 10)
+		samples := make([]int16, 1)
+		tone.Render(samples)
+		if want := int16(maxAmplitude / 5); samples[0] != want {
+			t.Errorf("expected %d but got %d", want, samples[0])
+		}
+	})
+	t.Run("channels are mixed", func(t *testing.T) {
+		tone := NewTone()
+		for c := range uint16(ToneChannels) {
+			tone.Write(c*toneRegisters+ToneFrequency, 100)
+			tone.Write(c*toneRegisters+ToneDuration, 10)
+		}
+		samples := make([]int16, 1)
+		tone.Render(samples)
+		if want := int16(ToneChannels * maxAmplitude); samples[0] != want {
+			t.Errorf("expected %d but got %d", want, samples[0])
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package device
+
+// The addresses of the UART and the offsets of its registers, which the assembler knows as UART_TX, UART_RX and
+// UART_STATUS
+const (
+	UARTAddress = ToneAddress + ToneSize
+	UARTSize    = 3
+	UARTTX      = 0
+	UARTRX      = 1
+	UARTStatus  = 2
+)
+
+// The bits of the status register of a UART
+const (
+	// UARTReceived is set while UARTRX holds a word that has not been acknowledged
+	UARTReceived = 1 << iota
+	// UARTSendable is set while there is room for a word written to UARTTX
+	UARTSendable
+)
+
+// UART is a Device which exchanges words with another UART, typically attached to another computer, with three
+// registers:
+//
+//   - Writing a word to UARTTX sends it to the other UART, unless its buffer is full in which case the word is dropped.
+//     Reads yield zero.
+//   - UARTRX holds the oldest received word that has not been acknowledged, or zero if there is none. Writing any value
+//     to it acknowledges the word, making room for the next one.
+//   - UARTStatus holds the UARTReceived and UARTSendable bits. Writes are ignored.
+//
+// The two ends of a link may be used from different goroutines.
+type UART struct {
+	tx chan<- uint16
+	rx <-chan uint16
+	// current is the word held by UARTRX, which is valid if ready is set
+	current uint16
+	ready   bool
+}
+
+// NewUARTPair creates two UARTs linked to each other, each able to hold buffer words sent by the other that it has not
+// yet received.
+func NewUARTPair(buffer int) (*UART, *UART) {
+	ab := make(chan uint16, buffer)
+	ba := make(chan uint16, buffer)
+	return &UART{tx: ab, rx: ba}, &UART{tx: ba, rx: ab}
+}
+
+func (u *UART) Read(offset uint16) uint16 {
+	switch offset {
+	case UARTRX:
+		u.fetch()
+		return u.current
+	case UARTStatus:
+		u.fetch()
+		var status uint16
+		if u.ready {
+			status |= UARTReceived
+		}
+		if len(u.tx) < cap(u.tx) {
+			status |= UARTSendable
+		}
+		return status
+	default:
+		return 0
+	}
+}
+
+func (u *UART) Write(offset uint16, value uint16) {
+	switch offset {
+	case UARTTX:
+		select {
+		case u.tx <- value:
+		default:
+		}
+	case UARTRX:
+		u.current = 0
+		u.ready = false
+	}
+}
+
+// fetch moves the next received word into UARTRX unless it still holds one
+func (u *UART) fetch() {
+	if u.ready {
+		return
+	}
+	select {
+	case w := <-u.rx:
+		u.current = w
+		u.ready = true
+	default:
+	}
+}
@@ -0,0 +1,42 @@
+package device
+
+import (
+	"testing"
+)
+
+func TestUART(t *testing.T) {
+	a, b := NewUARTPair(2)
+	expect := func(t *testing.T, u *UART, offset uint16, want uint16) {
+		t.Helper()
+		if actual := u.Read(offset); actual != want {
+			t.Errorf("expected %d but got %d", want, actual)
+		}
+	}
+	t.Run("idle", func(t *testing.T) {
+		expect(t, a, UARTStatus, UARTSendable)
+		expect(t, b, UARTRX, 0)
+	})
+	t.Run("send until the buffer is full", func(t *testing.T) {
+		a.Write(UARTTX, 1)
+		a.Write(UARTTX, 2)
+		expect(t, a, UARTStatus, 0)
+		a.Write(UARTTX, 3)
+	})
+	t.Run("received word is held until acknowledged", func(t *testing.T) {
+		expect(t, b, UARTStatus, UARTReceived|UARTSendable)
+		expect(t, b, UARTRX, 1)
+		expect(t, b, UARTRX, 1)
+		b.Write(UARTRX, 0)
+		expect(t, b, UARTRX, 2)
+		b.Write(UARTRX, 0)
+	})
+	t.Run("words sent to a full buffer are dropped", func(t *testing.T) {
+		expect(t, b, UARTStatus, UARTSendable)
+		expect(t, b, UARTRX, 0)
+	})
+	t.Run("both directions", func(t *testing.T) {
+		b.Write(UARTTX, 9)
+		expect(t, a, UARTStatus, UARTReceived|UARTSendable)
+		expect(t, a, UARTRX, 9)
+	})
+}
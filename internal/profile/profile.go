@@ -0,0 +1,217 @@
+// Package profile implements an instruction-level profiler for programs running on a chip.Computer. Profiles are written
+// in the pprof protobuf format, which means that `go tool pprof` can be used to find hot loops and render flame graphs of
+// the Hack program rather than of the simulator running it.
+//
+// The profiles attribute cycles to the label-delimited routines of the assembly source code. Attributing them to VM
+// functions and call stacks requires a source map annotated by the VM translator that generated the assembly, see
+// asm.Source, which none of the tools in this repository produce.
+package profile
+
+import (
+	"fmt"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/google/pprof/profile"
+	"io"
+	"sort"
+	"strings"
+)
+
+// unlabelled is the name given to instructions which do not belong to any routine
+const unlabelled = "(unlabelled)"
+
+// key identifies the samples collected at a given program counter with a given set of frames on the call stack
+type key struct {
+	stack int
+	pc    uint16
+}
+
+// Profiler is a chip.Tracer that counts how many times each instruction in ROM is executed.
+//
+// Given the asm.SourceMap of the program it also attributes cycles to label-delimited routines. Only if the map has
+// been annotated with the VM code the program was translated from, which the assembler does not do, are cycles
+// attributed to VM functions as well. Taking a jump that such a map marks as a call pushes a frame onto the call stack
+// of the profiler and taking one marked as a return pops it, which allows the written profile to describe whole call
+// stacks. Without annotations every sample has an empty call stack.
+type Profiler struct {
+	counts [chip.ROMSize]uint64
+	source *asm.SourceMap
+
+	// frames holds the ROM addresses of the jump instructions that made the VM function calls which are yet to return
+	frames []uint16
+	stacks map[string]int
+	// traces holds the call sites of each known stack, indexed by the stack identifiers kept in stacks
+	traces  [][]uint16
+	stack   int
+	samples map[key]uint64
+	jumped  bool
+	last    uint16
+}
+
+// New creates a Profiler for the program described by source. Source may be nil, in which case only the executions of
+// each ROM address are counted.
+func New(source *asm.SourceMap) *Profiler {
+	return &Profiler{
+		source:  source,
+		stacks:  map[string]int{"": 0},
+		traces:  [][]uint16{nil},
+		samples: make(map[key]uint64),
+	}
+}
+
+func (p *Profiler) Trace(c chip.Cycle) error {
+	if jump, ok := p.source.Lookup(p.last); ok && p.jumped {
+		if jump.Call {
+			p.frames = append(p.frames, p.last)
+			p.stack = p.identify()
+		} else if jump.Return && len(p.frames) > 0 {
+			p.frames = p.frames[:len(p.frames)-1]
+			p.stack = p.identify()
+		}
+	}
+	p.counts[c.PC]++
+	p.samples[key{stack: p.stack, pc: c.PC}]++
+	p.jumped, p.last = c.Jump, c.PC
+	return nil
+}
+
+// identify returns the identifier of the current call stack, which is only computed when the stack changes
+func (p *Profiler) identify() int {
+	var sb strings.Builder
+	for _, site := range p.frames {
+		fmt.Fprintf(&sb, "%d,", site)
+	}
+	if id, ok := p.stacks[sb.String()]; ok {
+		return id
+	}
+	sites := make([]uint16, len(p.frames))
+	for i, site := range p.frames {
+		sites[len(sites)-1-i] = site
+	}
+	p.traces = append(p.traces, sites)
+	p.stacks[sb.String()] = len(p.traces) - 1
+	return len(p.traces) - 1
+}
+
+// Count returns the number of times the instruction at addr has been executed.
+func (p *Profiler) Count(addr uint16) uint64 {
+	return p.counts[addr]
+}
+
+// Routines returns the number of cycles spent within each label-delimited routine.
+func (p *Profiler) Routines() map[string]uint64 {
+	cycles := make(map[string]uint64)
+	for addr, n := range p.counts {
+		if n > 0 {
+			cycles[p.routine(uint16(addr))] += n
+		}
+	}
+	return cycles
+}
+
+// Functions returns the number of cycles spent within each VM function, not counting the cycles spent in the functions
+// that it calls. It is empty unless the source map has been annotated with VM functions.
+func (p *Profiler) Functions() map[string]uint64 {
+	cycles := make(map[string]uint64)
+	for addr, n := range p.counts {
+		if source, ok := p.source.Lookup(uint16(addr)); ok && n > 0 && source.Function != "" {
+			cycles[source.Function] += n
+		}
+	}
+	return cycles
+}
+
+func (p *Profiler) routine(addr uint16) string {
+	if source, ok := p.source.Lookup(addr); ok && source.Routine != "" {
+		return source.Routine
+	}
+	return unlabelled
+}
+
+// line returns the line of the source code that the instruction at addr was assembled from, or 0 if it is unknown
+func (p *Profiler) line(addr uint16) int64 {
+	source, _ := p.source.Lookup(addr)
+	return int64(source.Line)
+}
+
+// Write writes the collected samples to w as a gzip compressed pprof profile. Every sample holds the number of cycles
+// spent on a single instruction, with the call sites of any active VM function calls as its callers when the source map
+// has been annotated with them.
+func (p *Profiler) Write(w io.Writer) error {
+	var file string
+	if p.source != nil {
+		file = p.source.File
+	}
+	binary := file
+	if binary == "" {
+		binary = "rom"
+	}
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "cycles", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "cycles", Unit: "count"},
+		Period:     1,
+	}
+	mapping := &profile.Mapping{
+		ID:              1,
+		Start:           0,
+		Limit:           chip.ROMSize,
+		File:            binary,
+		HasFunctions:    true,
+		HasFilenames:    file != "",
+		HasLineNumbers:  p.source != nil,
+		HasInlineFrames: false,
+	}
+	prof.Mapping = append(prof.Mapping, mapping)
+	functions := make(map[string]*profile.Function)
+	locations := make(map[uint16]*profile.Location)
+	location := func(addr uint16) *profile.Location {
+		if loc, ok := locations[addr]; ok {
+			return loc
+		}
+		name := p.routine(addr)
+		fn, ok := functions[name]
+		if !ok {
+			fn = &profile.Function{
+				ID:         uint64(len(functions) + 1),
+				Name:       name,
+				SystemName: name,
+				Filename:   file,
+			}
+			functions[name] = fn
+			prof.Function = append(prof.Function, fn)
+		}
+		loc := &profile.Location{
+			ID:      uint64(len(locations) + 1),
+			Mapping: mapping,
+			Address: uint64(addr),
+			Line:    []profile.Line{{Function: fn, Line: p.line(addr)}},
+		}
+		locations[addr] = loc
+		prof.Location = append(prof.Location, loc)
+		return loc
+	}
+	keys := make([]key, 0, len(p.samples))
+	for k := range p.samples {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].stack != keys[j].stack {
+			return keys[i].stack < keys[j].stack
+		}
+		return keys[i].pc < keys[j].pc
+	})
+	for _, k := range keys {
+		sample := &profile.Sample{
+			Location: []*profile.Location{location(k.pc)},
+			Value:    []int64{int64(p.samples[k])},
+		}
+		for _, site := range p.traces[k.stack] {
+			sample.Location = append(sample.Location, location(site))
+		}
+		prof.Sample = append(prof.Sample, sample)
+	}
+	if err := prof.CheckValid(); err != nil {
+		return err
+	}
+	return prof.Write(w)
+}
@@ -0,0 +1,160 @@
+package profile
+
+import (
+	"bytes"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/google/pprof/profile"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// program calls Main.main from Sys.init the way translated VM code does, with Main.main counting down from 3 to 0 before
+// returning to Sys.init which then spins in an infinite loop
+const program = `(Sys.init)
+@Sys.init$ret.0
+D=A
+@R14
+M=D
+@Main.main
+0;JMP
+(Sys.init$ret.0)
+(Sys.init$END)
+@Sys.init$END
+0;JMP
+(Main.main)
+@3
+D=A
+(Main.main$LOOP)
+D=D-1
+@Main.main$LOOP
+D;JGT
+@R14
+A=M
+0;JMP
+`
+
+func run(t *testing.T, ticks int) *Profiler {
+	t.Helper()
+	bin, source, err := asm.AssembleMapped("main.asm", strings.NewReader(program))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Annotate the map the way a VM translator would: Sys.init spans addresses 0 through 7 and calls Main.main from
+	// address 5, while Main.main spans addresses 8 through 15 and returns from address 15
+	for addr := range source.Sources {
+		source.Sources[addr].Function = "Sys.init"
+		if addr >= 8 {
+			source.Sources[addr].Function = "Main.main"
+		}
+	}
+	source.Sources[5].Call = true
+	source.Sources[15].Return = true
+	p := New(source)
+	c := chip.NewComputer(chip.NewROM(bin))
+	c.Trace(p)
+	for range ticks {
+		if err := c.Tick(chip.Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return p
+}
+
+func TestProfiler(t *testing.T) {
+	// Sys.init runs 6 instructions before calling Main.main, which runs 2 instructions before looping 3 times over 3
+	// instructions and then returns in 3 instructions. The remaining 6 ticks are spent in the 2 instruction long END loop.
+	p := run(t, 6+2+9+3+6)
+	t.Run("counts executions per address", func(t *testing.T) {
+		if p.Count(0) != 1 {
+			t.Errorf("expected 1 execution of address 0 but got %d", p.Count(0))
+		}
+		// The first instruction of Main.main$LOOP
+		if p.Count(10) != 3 {
+			t.Errorf("expected 3 executions of address 10 but got %d", p.Count(10))
+		}
+		if p.Count(6) != 3 {
+			t.Errorf("expected 3 executions of address 6 but got %d", p.Count(6))
+		}
+	})
+	t.Run("counts cycles per routine", func(t *testing.T) {
+		expected := map[string]uint64{
+			"Sys.init":       6,
+			"Sys.init$END":   6,
+			"Main.main":      2,
+			"Main.main$LOOP": 12,
+		}
+		if actual := p.Routines(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %v but got %v", expected, actual)
+		}
+	})
+	t.Run("counts cycles per VM function", func(t *testing.T) {
+		expected := map[string]uint64{
+			"Sys.init":  12,
+			"Main.main": 14,
+		}
+		if actual := p.Functions(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %v but got %v", expected, actual)
+		}
+	})
+	t.Run("writes a pprof profile", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := p.Write(&out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		prof, err := profile.Parse(&out)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var total int64
+		stacks := make(map[uint64][]string)
+		for _, s := range prof.Sample {
+			total += s.Value[0]
+			var stack []string
+			for _, loc := range s.Location {
+				stack = append(stack, loc.Line[0].Function.Name)
+			}
+			stacks[s.Location[0].Address] = stack
+		}
+		if total != 26 {
+			t.Errorf("expected 26 cycles but got %d", total)
+		}
+		// Instructions of Main.main are called from the jump at the end of the calling sequence in Sys.init
+		if expected := []string{"Main.main$LOOP", "Sys.init"}; !reflect.DeepEqual(stacks[10], expected) {
+			t.Errorf("expected stack %v but got %v", expected, stacks[10])
+		}
+		if expected := []string{"Sys.init$END"}; !reflect.DeepEqual(stacks[6], expected) {
+			t.Errorf("expected stack %v but got %v", expected, stacks[6])
+		}
+		if line := prof.Sample[0].Location[0].Line[0].Line; line != 2 {
+			t.Errorf("expected first sample on line 2 but got %d", line)
+		}
+	})
+}
+
+func TestProfiler_unannotated(t *testing.T) {
+	// Without annotations from a VM translator the labels of the program are not taken for VM functions, however they
+	// happen to be named
+	bin, source, err := asm.AssembleMapped("main.asm", strings.NewReader(program))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := New(source)
+	c := chip.NewComputer(chip.NewROM(bin))
+	c.Trace(p)
+	for range 26 {
+		if err := c.Tick(chip.Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if functions := p.Functions(); len(functions) != 0 {
+		t.Errorf("expected no VM functions but got %v", functions)
+	}
+	if len(p.traces) != 1 {
+		t.Errorf("expected only the empty call stack but got %v", p.traces)
+	}
+	if n := p.Routines()["Main.main$LOOP"]; n != 12 {
+		t.Errorf("expected 12 cycles in Main.main$LOOP but got %d", n)
+	}
+}
@@ -0,0 +1,52 @@
+package simulator
+
+import "time"
+
+// Unlimited is the clock rate at which the simulator executes instructions as fast as it is able to.
+const Unlimited uint64 = 0
+
+// maxBacklog bounds how far behind its target rate the clock may fall before it gives up on catching up. Without it a
+// long pause, such as the window being dragged around, would be followed by a burst of instructions at full speed.
+const maxBacklog = 100 * time.Millisecond
+
+// clock paces the execution of instructions to a target rate.
+type clock struct {
+	rate uint64
+	last time.Time
+	// owed is the number of instructions, or fractions thereof, that are due but have not been executed yet
+	owed float64
+}
+
+// reset restarts the clock at now, forgetting about any instructions that are due.
+func (c *clock) reset(now time.Time) {
+	c.last = now
+	c.owed = 0
+}
+
+// due returns the number of instructions that should be executed at now to keep up with the target rate. The clock
+// assumes that all of them are executed before it is asked again.
+func (c *clock) due(now time.Time) uint64 {
+	if c.last.IsZero() {
+		c.last = now
+	}
+	elapsed := now.Sub(c.last)
+	c.last = now
+	if elapsed > maxBacklog {
+		elapsed = maxBacklog
+	}
+	c.owed += elapsed.Seconds() * float64(c.rate)
+	if limit := maxBacklog.Seconds() * float64(c.rate); c.owed > limit {
+		c.owed = limit
+	}
+	n := uint64(c.owed)
+	c.owed -= float64(n)
+	return n
+}
+
+// wait returns how long it takes until the next instruction is due.
+func (c *clock) wait() time.Duration {
+	if c.rate == Unlimited {
+		return 0
+	}
+	return time.Duration((1 - c.owed) / float64(c.rate) * float64(time.Second))
+}
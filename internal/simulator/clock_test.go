@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock_due(t *testing.T) {
+	start := time.Unix(0, 0)
+	var assertions = []struct {
+		name    string
+		rate    uint64
+		elapsed []time.Duration
+		due     []uint64
+	}{
+		{
+			name:    "whole instructions",
+			rate:    1000,
+			elapsed: []time.Duration{0, time.Millisecond, 10 * time.Millisecond},
+			due:     []uint64{0, 1, 10},
+		},
+		{
+			name:    "fractions carry over",
+			rate:    10,
+			elapsed: []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 25 * time.Millisecond, 75 * time.Millisecond},
+			due:     []uint64{0, 1, 0, 1},
+		},
+		{
+			name:    "backlog is bounded",
+			rate:    1000,
+			elapsed: []time.Duration{time.Hour, time.Millisecond},
+			due:     []uint64{100, 1},
+		},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			c := clock{rate: assert.rate}
+			c.reset(start)
+			now := start
+			for i, elapsed := range assert.elapsed {
+				now = now.Add(elapsed)
+				if n := c.due(now); n != assert.due[i] {
+					t.Errorf("expected %d instructions due after step %d but got %d", assert.due[i], i, n)
+				}
+			}
+		})
+	}
+}
+
+func TestClock_wait(t *testing.T) {
+	c := clock{rate: 4}
+	c.reset(time.Unix(0, 0))
+	if wait := c.wait(); wait != 250*time.Millisecond {
+		t.Errorf("expected to wait 250ms but got %v", wait)
+	}
+	c.due(time.Unix(0, 0).Add(100 * time.Millisecond))
+	if wait := c.wait(); wait != 150*time.Millisecond {
+		t.Errorf("expected to wait 150ms but got %v", wait)
+	}
+	if wait := (&clock{rate: Unlimited}).wait(); wait != 0 {
+		t.Errorf("expected not to wait at an unlimited rate but got %v", wait)
+	}
+}
@@ -0,0 +1,51 @@
+package simulator
+
+import "sync/atomic"
+
+// Frame is a copy of the screen memory map of the simulated computer, taken between two instructions.
+type Frame struct {
+	Words []uint16
+	// seq numbers frames in the order in which they were published, starting at 1
+	seq atomic.Uint64
+}
+
+// Seq returns the sequence number of the frame. Frames published later have higher sequence numbers.
+func (f *Frame) Seq() uint64 {
+	return f.seq.Load()
+}
+
+// frames hands frames from a single writer over to a single reader without either of them ever waiting on the other. It
+// is a triple buffer: the writer owns the back frame, the reader owns the front frame and the middle frame is exchanged
+// atomically by both. Neither party touches a frame that the other owns.
+type frames struct {
+	back   *Frame
+	middle atomic.Pointer[Frame]
+	front  *Frame
+	seq    uint64
+}
+
+func newFrames(words int) *frames {
+	f := &frames{
+		back:  &Frame{Words: make([]uint16, words)},
+		front: &Frame{Words: make([]uint16, words)},
+	}
+	f.middle.Store(&Frame{Words: make([]uint16, words)})
+	return f
+}
+
+// publish lets fill draw the next frame and then makes it available to the reader. It must only be called by the writer.
+func (f *frames) publish(fill func(words []uint16)) {
+	fill(f.back.Words)
+	f.seq++
+	f.back.seq.Store(f.seq)
+	f.back = f.middle.Swap(f.back)
+}
+
+// latest returns the most recently published frame, which remains valid until latest is called again. It must only be
+// called by the reader.
+func (f *frames) latest() *Frame {
+	if f.middle.Load().Seq() > f.front.Seq() {
+		f.front = f.middle.Swap(f.front)
+	}
+	return f.front
+}
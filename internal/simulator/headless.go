@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"time"
+)
+
+// Headless runs a Machine without displaying its screen or taking keyboard input, for programs that communicate through
+// other devices or that are only run for their side effects, such as traces and snapshots.
+type Headless struct {
+	machine *Machine
+	tone    *device.Tone
+	wav     *WAVWriter
+	// recorded is the number of samples written to wav
+	recorded int
+}
+
+func NewHeadless(rom chip.ROM) *Headless {
+	return &Headless{machine: NewMachine(rom)}
+}
+
+// Fill configures the simulated computer to execute instr for every address beyond the end of its program instead of
+// halting. It must be called before Run.
+func (h *Headless) Fill(instr [16]chip.Signal) {
+	h.machine.Computer().Fill(instr)
+}
+
+// Trace installs a Tracer in the simulated computer. It must be called before Run and the Tracer is called from the
+// goroutine running the computer.
+func (h *Headless) Trace(t chip.Tracer) {
+	h.machine.Computer().Trace(t)
+}
+
+// Snapshot captures the current state of the simulated computer. It must not be called while Run is running.
+func (h *Headless) Snapshot() chip.Snapshot {
+	return h.machine.Computer().Snapshot()
+}
+
+// Restore replaces the state of the simulated computer with the state captured in snapshot. It must be called before
+// Run.
+func (h *Headless) Restore(snapshot chip.Snapshot) error {
+	return h.machine.Computer().Restore(snapshot)
+}
+
+// Attach maps a device into the memory of the simulated computer, see chip.Bus.Attach. It must be called before Run.
+func (h *Headless) Attach(name string, start uint16, size int, device chip.Device) error {
+	return h.machine.Attach(name, start, size, device)
+}
+
+// SetClockRate sets the rate, in instructions per second, at which the simulated computer is run. The rate can be
+// Unlimited, which is also the default.
+func (h *Headless) SetClockRate(hz uint64) {
+	h.machine.SetClockRate(hz)
+}
+
+// Record writes the sound of tone to wav while Run is running, in real time. It must be called before Run.
+func (h *Headless) Record(tone *device.Tone, wav *WAVWriter) {
+	h.tone = tone
+	h.wav = wav
+}
+
+// Run runs the simulated computer until either it halts or ctx is done. Unlike the SDLSimulator, it does not need to be
+// called from the main OS thread. The error which halted the computer is returned, joined with the error which stopped
+// the recording of the sound, if any.
+func (h *Headless) Run(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	start := time.Now()
+	var recording error
+	defer func() {
+		cancel()
+		<-h.machine.Done()
+		if recording == nil {
+			recording = h.record(start)
+		}
+		err = errors.Join(h.machine.Stats().Err, recording)
+	}()
+	go h.machine.Run(ctx)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for !h.machine.Stats().Halted {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if recording == nil {
+			recording = h.record(start)
+		}
+	}
+	return nil
+}
+
+// record writes the samples that are due since start, if recording. Recording stops at the first error.
+func (h *Headless) record(start time.Time) error {
+	if h.wav == nil {
+		return nil
+	}
+	n := samplesDue(time.Since(start)) - h.recorded
+	if n <= 0 {
+		return nil
+	}
+	samples := make([]int16, n)
+	h.tone.Render(samples)
+	if err := h.wav.Write(samples); err != nil {
+		h.wav = nil
+		return err
+	}
+	h.recorded += n
+	return nil
+}
@@ -0,0 +1,88 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeadless_Run(t *testing.T) {
+	rom, err := (&asm.Builder{Devices: true}).
+		A("104").C("D", "A", "").
+		A("SERIAL_TX").C("M", "D", "").
+		A("105").C("D", "A", "").
+		A("SERIAL_TX").C("M", "D", "").
+		ROM()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out strings.Builder
+	h := NewHeadless(rom)
+	if err := h.Attach("serial", device.SerialAddress, device.SerialSize, device.NewSerial(nil, &out)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Run returns by itself once the computer halts at the end of the program, reporting why it halted
+	if err := h.Run(context.Background()); !errors.Is(err, chip.ErrHalted) {
+		t.Errorf("expected %v but got %v", chip.ErrHalted, err)
+	}
+	if out.String() != "hi" {
+		t.Errorf("expected %q but got %q", "hi", out.String())
+	}
+}
+
+func TestHeadless_Record(t *testing.T) {
+	// Plays a 50ms tone and waits for it to end before halting
+	rom, err := (&asm.Builder{Devices: true}).
+		A("440").C("D", "A", "").
+		A("TONE0_FREQUENCY").C("M", "D", "").
+		A("50").C("D", "A", "").
+		A("TONE0_DURATION").C("M", "D", "").
+		At("WAIT").
+		A("TONE0_DURATION").C("D", "M", "").
+		A("WAIT").C("", "D", "JNE").
+		ROM()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "sound.wav"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	wav, err := NewWAVWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tone := device.NewTone()
+	h := NewHeadless(rom)
+	if err := h.Attach("tone", device.ToneAddress, device.ToneSize, tone); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h.Record(tone, wav)
+	if err := h.Run(context.Background()); !errors.Is(err, chip.ErrHalted) {
+		t.Errorf("expected %v but got %v", chip.ErrHalted, err)
+	}
+	if err := wav.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The tone only ends once it has been recorded in full, surrounded by however much silence happened to be recorded
+	var sounding int
+	for i := wavHeaderSize; i < len(data); i += 2 {
+		if data[i] != 0 || data[i+1] != 0 {
+			sounding++
+		}
+	}
+	if want := 50 * device.SampleRate / 1000; sounding != want {
+		t.Errorf("expected %d samples of sound but got %d", want, sounding)
+	}
+}
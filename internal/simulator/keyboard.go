@@ -0,0 +1,196 @@
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keymap translates keyboard input into the key codes of the Hack platform. Printable characters are taken from SDL text
+// input, which means that the layout of the keyboard, Shift and Caps Lock are all accounted for by the operating system,
+// while keys that do not produce text are identified by their SDL key code.
+type Keymap struct {
+	keys  map[sdl.Keycode]uint16
+	chars map[rune]uint16
+}
+
+// DefaultKeymap returns a Keymap which maps printable ASCII characters to themselves and special keys to the codes given
+// to them by the Hack platform.
+func DefaultKeymap() *Keymap {
+	k := &Keymap{
+		keys: map[sdl.Keycode]uint16{
+			sdl.K_RETURN:    128,
+			sdl.K_KP_ENTER:  128,
+			sdl.K_BACKSPACE: 129,
+			sdl.K_LEFT:      130,
+			sdl.K_UP:        131,
+			sdl.K_RIGHT:     132,
+			sdl.K_DOWN:      133,
+			sdl.K_HOME:      134,
+			sdl.K_END:       135,
+			sdl.K_PAGEUP:    136,
+			sdl.K_PAGEDOWN:  137,
+			sdl.K_INSERT:    138,
+			sdl.K_DELETE:    139,
+			sdl.K_ESCAPE:    140,
+		},
+		chars: make(map[rune]uint16),
+	}
+	for i, key := range []sdl.Keycode{
+		sdl.K_F1, sdl.K_F2, sdl.K_F3, sdl.K_F4, sdl.K_F5, sdl.K_F6,
+		sdl.K_F7, sdl.K_F8, sdl.K_F9, sdl.K_F10, sdl.K_F11, sdl.K_F12,
+	} {
+		k.keys[key] = 141 + uint16(i)
+	}
+	for c := ' '; c <= '~'; c++ {
+		k.chars[c] = uint16(c)
+	}
+	return k
+}
+
+// LoadKeymap reads a keymap which extends and overrides the DefaultKeymap. Every line maps either a character or a key to
+// a Hack key code, written as a decimal number after the character or key and separated from it by whitespace. Lines
+// holding a single character before the code map text input, which is how characters outside of ASCII are made
+// available, while longer names refer to keys by their SDL key name such as "F1" or "Keypad Enter". Mapping to code 0
+// disables a character or key. Empty lines and lines starting with # are ignored.
+//
+//	# Scandinavian letters shown as their closest ASCII relatives
+//	å 97
+//	ö 111
+//	Keypad Enter 128
+func LoadKeymap(r io.Reader) (*Keymap, error) {
+	k := DefaultKeymap()
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		split := strings.LastIndexFunc(text, func(r rune) bool {
+			return r == ' ' || r == '\t'
+		})
+		if split < 0 {
+			return nil, fmt.Errorf("line %d: missing key code", line)
+		}
+		name, value := strings.TrimSpace(text[:split]), text[split+1:]
+		code, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid key code '%s'", line, value)
+		}
+		if c, size := utf8.DecodeRuneInString(name); size == len(name) && c != utf8.RuneError {
+			k.chars[c] = uint16(code)
+			continue
+		}
+		key := sdl.GetKeyFromName(name)
+		if key == sdl.K_UNKNOWN {
+			return nil, fmt.Errorf("line %d: unknown key '%s'", line, name)
+		}
+		k.keys[key] = uint16(code)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Key returns the code of a key which does not produce text. False is returned if the key is not mapped.
+func (k *Keymap) Key(key sdl.Keycode) (uint16, bool) {
+	code := k.keys[key]
+	return code, code != 0
+}
+
+// Char returns the code of a character produced by text input. False is returned if the character is not mapped.
+func (k *Keymap) Char(c rune) (uint16, bool) {
+	code := k.chars[c]
+	return code, code != 0
+}
+
+// keyboard tracks the keys that are held down in order to decide which code the keyboard memory map should hold. Like a
+// real keyboard it shows the most recently pressed key that is still held down, or 0 once every key has been released.
+//
+// Characters arrive as text input after the key press that produced them, so a key that is not mapped by itself is held
+// as pending until its text arrives and is then credited with the character.
+//
+// When queued is set the codes are passed on to a keyboard in queued mode, see chip.Keyboard.Queue, which queues every
+// code it is given. Only presses and text then produce codes, as releasing a key must not queue the key still held.
+type keyboard struct {
+	keymap *Keymap
+	queued bool
+	// held lists the keys that are held down and the codes they produced in the order in which they were pressed
+	held    []held
+	pending sdl.Scancode
+}
+
+type held struct {
+	scancode sdl.Scancode
+	code     uint16
+}
+
+// current returns the code that the keyboard memory map should hold
+func (k *keyboard) current() uint16 {
+	if len(k.held) == 0 {
+		return 0
+	}
+	return k.held[len(k.held)-1].code
+}
+
+// hold records that the key identified by scancode produced code, returning the new code of the keyboard
+func (k *keyboard) hold(scancode sdl.Scancode, code uint16) uint16 {
+	k.forget(scancode)
+	k.held = append(k.held, held{scancode: scancode, code: code})
+	return k.current()
+}
+
+func (k *keyboard) forget(scancode sdl.Scancode) bool {
+	for i, h := range k.held {
+		if h.scancode == scancode {
+			k.held = append(k.held[:i], k.held[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// press handles a key being pressed. The new code of the keyboard is returned unless it remains unchanged until the text
+// input of the key arrives.
+func (k *keyboard) press(key sdl.Keysym) (uint16, bool) {
+	if code, ok := k.keymap.Key(key.Sym); ok {
+		k.pending = sdl.SCANCODE_UNKNOWN
+		return k.hold(key.Scancode, code), true
+	}
+	k.pending = key.Scancode
+	return 0, false
+}
+
+// text handles text input, which is credited to the most recently pressed key. False is returned if the text does not
+// change the code of the keyboard.
+func (k *keyboard) text(text string) (uint16, bool) {
+	c, size := utf8.DecodeRuneInString(text)
+	if k.pending == sdl.SCANCODE_UNKNOWN || size != len(text) {
+		return 0, false
+	}
+	scancode := k.pending
+	k.pending = sdl.SCANCODE_UNKNOWN
+	code, ok := k.keymap.Char(c)
+	if !ok {
+		return 0, false
+	}
+	return k.hold(scancode, code), true
+}
+
+// release handles a key being released. False is returned if the key did not contribute to the code of the keyboard,
+// and always when queued.
+func (k *keyboard) release(key sdl.Keysym) (uint16, bool) {
+	if key.Scancode == k.pending {
+		k.pending = sdl.SCANCODE_UNKNOWN
+	}
+	before := k.current()
+	if !k.forget(key.Scancode) || k.queued {
+		return 0, false
+	}
+	return k.current(), k.current() != before
+}
@@ -0,0 +1,109 @@
+package simulator
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadKeymap(t *testing.T) {
+	k, err := LoadKeymap(strings.NewReader("# comment\n\nö 111\nKeypad 0 48\nF12 0\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var assertions = []struct {
+		name string
+		code func() (uint16, bool)
+		want uint16
+		ok   bool
+	}{
+		{name: "mapped character", code: func() (uint16, bool) { return k.Char('ö') }, want: 111, ok: true},
+		{name: "default character", code: func() (uint16, bool) { return k.Char('!') }, want: 33, ok: true},
+		{name: "unmapped character", code: func() (uint16, bool) { return k.Char('ä') }},
+		{name: "mapped key", code: func() (uint16, bool) { return k.Key(sdl.K_KP_0) }, want: 48, ok: true},
+		{name: "default key", code: func() (uint16, bool) { return k.Key(sdl.K_F1) }, want: 141, ok: true},
+		{name: "disabled key", code: func() (uint16, bool) { return k.Key(sdl.K_F12) }},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			code, ok := assert.code()
+			if code != assert.want || ok != assert.ok {
+				t.Errorf("expected %d, %v but got %d, %v", assert.want, assert.ok, code, ok)
+			}
+		})
+	}
+	t.Run("errors", func(t *testing.T) {
+		for _, src := range []string{"F1", "F1 65536", "F1 x", "Hyperspace 3"} {
+			if _, err := LoadKeymap(strings.NewReader(src)); err == nil {
+				t.Errorf("expected error for %q but got nil", src)
+			}
+		}
+	})
+}
+
+func TestKeyboard(t *testing.T) {
+	shift := sdl.Keysym{Scancode: sdl.SCANCODE_LSHIFT, Sym: sdl.K_LSHIFT}
+	one := sdl.Keysym{Scancode: sdl.SCANCODE_1, Sym: sdl.K_1}
+	home := sdl.Keysym{Scancode: sdl.SCANCODE_HOME, Sym: sdl.K_HOME}
+	k := keyboard{keymap: DefaultKeymap()}
+
+	expect := func(t *testing.T, code uint16, ok bool, want uint16, changed bool) {
+		t.Helper()
+		if ok != changed || (ok && code != want) {
+			t.Errorf("expected %d, %v but got %d, %v", want, changed, code, ok)
+		}
+	}
+	t.Run("shift produces no code by itself", func(t *testing.T) {
+		code, ok := k.press(shift)
+		expect(t, code, ok, 0, false)
+	})
+	t.Run("shifted digit is taken from text input", func(t *testing.T) {
+		code, ok := k.press(one)
+		expect(t, code, ok, 0, false)
+		code, ok = k.text("!")
+		expect(t, code, ok, '!', true)
+	})
+	t.Run("special key overrides held character", func(t *testing.T) {
+		code, ok := k.press(home)
+		expect(t, code, ok, 134, true)
+	})
+	t.Run("releasing the latest key reveals the one still held", func(t *testing.T) {
+		code, ok := k.release(home)
+		expect(t, code, ok, '!', true)
+	})
+	t.Run("releasing shift does not change anything", func(t *testing.T) {
+		code, ok := k.release(shift)
+		expect(t, code, ok, 0, false)
+	})
+	t.Run("releasing the last key clears the keyboard", func(t *testing.T) {
+		code, ok := k.release(one)
+		expect(t, code, ok, 0, true)
+	})
+	t.Run("text without a key press is ignored", func(t *testing.T) {
+		code, ok := k.text("a")
+		expect(t, code, ok, 0, false)
+	})
+}
+
+func TestKeyboard_queued(t *testing.T) {
+	a := sdl.Keysym{Scancode: sdl.SCANCODE_A, Sym: sdl.K_a}
+	b := sdl.Keysym{Scancode: sdl.SCANCODE_B, Sym: sdl.K_b}
+	k := keyboard{keymap: DefaultKeymap(), queued: true}
+	// Every code that is passed on is queued, like SDLSimulator.Update passes them on to a queued Machine
+	var queued []uint16
+	pass := func(code uint16, ok bool) {
+		if ok {
+			queued = append(queued, code)
+		}
+	}
+	pass(k.press(a))
+	pass(k.text("a"))
+	pass(k.press(b))
+	pass(k.text("b"))
+	pass(k.release(b))
+	pass(k.release(a))
+	if expected := []uint16{'a', 'b'}; !reflect.DeepEqual(queued, expected) {
+		t.Errorf("expected %v but got %v", expected, queued)
+	}
+}
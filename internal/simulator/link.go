@@ -0,0 +1,25 @@
+package simulator
+
+import (
+	"github.com/crookdc/nand2tetris/internal/device"
+)
+
+// linkBuffer is the number of words that either end of a link holds before further words sent to it are dropped
+const linkBuffer = 64
+
+// Link connects the computers of a and b through a pair of device.UART. If lockstep is set, a leads b as described by
+// Machine.Lead, and otherwise the machines run independently of each other at their own clock rates. Link must be called
+// before either machine is run.
+func Link(a, b *Machine, lockstep bool) error {
+	ua, ub := device.NewUARTPair(linkBuffer)
+	if err := a.Attach("uart", device.UARTAddress, device.UARTSize, ua); err != nil {
+		return err
+	}
+	if err := b.Attach("uart", device.UARTAddress, device.UARTSize, ub); err != nil {
+		return err
+	}
+	if lockstep {
+		a.Lead(b)
+	}
+	return nil
+}
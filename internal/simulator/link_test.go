@@ -0,0 +1,89 @@
+package simulator
+
+import (
+	"context"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"testing"
+)
+
+// sender sends 42 over the UART and then loops forever
+func sender(t *testing.T) chip.ROM {
+	t.Helper()
+	rom, err := (&asm.Builder{Devices: true}).
+		A("42").C("D", "A", "").
+		A("UART_TX").C("M", "D", "").
+		At("LOOP").
+		A("LOOP").C("", "0", "JMP").
+		ROM()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rom
+}
+
+// receiver continuously copies words received over the UART into the first word of the screen
+func receiver(t *testing.T) chip.ROM {
+	t.Helper()
+	rom, err := (&asm.Builder{Devices: true}).
+		At("WAIT").
+		A("UART_STATUS").C("D", "M", "").
+		A("1").C("D", "D&A", "").
+		A("WAIT").C("", "D", "JEQ").
+		A("UART_RX").C("D", "M", "").
+		C("M", "0", "").
+		A("SCREEN").C("M", "D", "").
+		A("WAIT").C("", "0", "JMP").
+		ROM()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rom
+}
+
+func TestLink(t *testing.T) {
+	t.Run("lockstep", func(t *testing.T) {
+		a, b := NewMachine(sender(t)), NewMachine(receiver(t))
+		if err := Link(a, b, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Starting out paused makes the interleaving of the two computers fully determined by the steps taken
+		a.paused = true
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go a.Run(ctx)
+		for range 20 {
+			b.Step()
+		}
+		if frame := b.Frame(); frame.Words[0] != 42 {
+			t.Errorf("expected %d but got %d", 42, frame.Words[0])
+		}
+		var cycles [2]uint64
+		a.Do(func(c *chip.Computer) { cycles[0] = c.Cycles() })
+		b.Do(func(c *chip.Computer) { cycles[1] = c.Cycles() })
+		if cycles[0] != 20 || cycles[1] != 20 {
+			t.Errorf("expected both computers to have executed 20 instructions but got %v", cycles)
+		}
+		if stats := b.Stats(); !stats.Paused {
+			t.Error("expected the follower to be paused along with its leader")
+		}
+		cancel()
+		<-b.Done()
+	})
+	t.Run("free-running", func(t *testing.T) {
+		a, b := NewMachine(sender(t)), NewMachine(receiver(t))
+		if err := Link(a, b, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go a.Run(ctx)
+		go b.Run(ctx)
+		await(t, func() bool {
+			return b.Frame().Words[0] == 42
+		})
+		cancel()
+		<-a.Done()
+		<-b.Done()
+	})
+}
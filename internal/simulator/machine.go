@@ -0,0 +1,365 @@
+package simulator
+
+import (
+	"context"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"sync/atomic"
+	"time"
+)
+
+// pollInterval is the longest time that the machine executes instructions for without handling keyboard input and calls
+// to Do
+const pollInterval = 10 * time.Millisecond
+
+// batch is the number of instructions executed between checks of the time when running at an unlimited clock rate
+const batch = 1024
+
+// keyQueue is the number of key codes held by the keyboard in queued mode, see Machine.QueueKeys
+const keyQueue = 64
+
+// keyBuffer is the number of keyboard events that can be queued up before senders have to wait for the machine
+const keyBuffer = 64
+
+// Stats describes the state of a running Machine.
+type Stats struct {
+	// IPS is the number of instructions per second executed during the most recently measured second
+	IPS uint64
+	// Rate is the target clock rate, or Unlimited
+	Rate   uint64
+	Paused bool
+	Halted bool
+	// Err is the error which halted the computer, nil while it has not halted
+	Err error
+}
+
+// Machine runs a chip.Computer on a goroutine of its own, independently of whatever displays its screen. The screen is
+// handed over through frames published at ScreenRefreshRateHz, keyboard input arrives over a channel and everything else
+// that needs to touch the computer while it runs is passed to Do.
+type Machine struct {
+	computer *chip.Computer
+	bus      *chip.Bus
+	keyboard chip.Keyboard
+	screen   chip.Block
+	clock    clock
+	paused   bool
+	halted   error
+	frames   *frames
+	keys     chan uint16
+	control  chan func()
+	done     chan struct{}
+	running  atomic.Bool
+	// leader runs the computer of the machine in lockstep with its own, see Lead, and follower is the machine it leads
+	leader   *Machine
+	follower *Machine
+
+	// executed counts the instructions executed since measured, which is when stats was last updated
+	executed  uint64
+	measured  time.Time
+	published time.Time
+	stats     atomic.Pointer[Stats]
+}
+
+// NewMachine creates a Machine running the program in rom at an unlimited clock rate. The memory of the computer is a bus
+// with the memory map of the Hack platform and a device.Timer, to which further devices can be attached.
+func NewMachine(rom chip.ROM) *Machine {
+	m := &Machine{
+		frames:  newFrames(chip.ScreenSize),
+		keys:    make(chan uint16, keyBuffer),
+		control: make(chan func()),
+		done:    make(chan struct{}),
+	}
+	m.bus = chip.NewHackBus(&m.keyboard)
+	screen, _ := m.bus.Device("screen")
+	m.screen = screen.(chip.Block)
+	m.computer = chip.NewComputerWithRAM(rom, m.bus)
+	// The timer is placed right after the keyboard, where nothing else is attached yet
+	_ = m.bus.Attach("timer", device.TimerAddress, device.TimerSize, device.NewTimer(m.computer.Cycles, time.Now))
+	m.stats.Store(&Stats{})
+	return m
+}
+
+// Attach maps a device into the memory of the computer, see chip.Bus.Attach. It must be called before Run and the device
+// is only ever accessed from the goroutine running the Machine.
+func (m *Machine) Attach(name string, start uint16, size int, device chip.Device) error {
+	return m.bus.Attach(name, start, size, device)
+}
+
+// QueueKeys switches the keyboard of the computer to queued mode, see chip.Keyboard, and attaches a
+// device.KeyboardStatus to it. It must be called before Run.
+func (m *Machine) QueueKeys() error {
+	m.keyboard.Queue(keyQueue)
+	return m.bus.Attach("keyboard-status", device.KeyboardStatusAddress, device.KeyboardStatusSize, device.KeyboardStatus{Keyboard: &m.keyboard})
+}
+
+// Lead makes follower execute an instruction after every instruction executed by m, on the goroutine running m, so that
+// the two computers run in lockstep at the clock rate of m. The follower must not be Run itself and stops when m does.
+// Pausing, stepping and setting the clock rate of the follower applies to m instead, while keyboard input, calls to Do
+// and frames of the screen are handled by m on behalf of the follower. Lead must be called before Run.
+func (m *Machine) Lead(follower *Machine) {
+	m.follower = follower
+	follower.leader = m
+}
+
+// Computer returns the simulated computer. It must not be used while the Machine is running, pass a function to Do
+// instead.
+func (m *Machine) Computer() *chip.Computer {
+	return m.computer
+}
+
+// Done returns a channel that is closed once Run has returned.
+func (m *Machine) Done() <-chan struct{} {
+	return m.done
+}
+
+// Frame returns the most recently published copy of the screen. It must only be called from a single goroutine and the
+// returned Frame remains valid until the next call.
+func (m *Machine) Frame() *Frame {
+	return m.frames.latest()
+}
+
+// Stats returns the most recently measured state of the Machine.
+func (m *Machine) Stats() Stats {
+	return *m.stats.Load()
+}
+
+// Key queues a press of the key with code, where code is zero when no key is pressed. Unless the keyboard is in queued
+// mode, the code is written to the keyboard memory map.
+func (m *Machine) Key(code uint16) {
+	select {
+	case m.keys <- code:
+	case <-m.done:
+	}
+}
+
+// Do executes fn on the goroutine running the Machine, in between two instructions, and waits for it to return. False is
+// returned without executing fn if the Machine has stopped running.
+func (m *Machine) Do(fn func(c *chip.Computer)) bool {
+	return m.do(func() {
+		fn(m.computer)
+	})
+}
+
+func (m *Machine) do(fn func()) bool {
+	executed := make(chan struct{})
+	select {
+	case m.control <- func() {
+		fn()
+		close(executed)
+	}:
+		<-executed
+		return true
+	case <-m.done:
+		return false
+	}
+}
+
+// SetClockRate sets the rate, in instructions per second, at which the computer is run. The rate can be Unlimited, which
+// is also the default. Unlike the other methods that control the Machine it may be called before Run.
+func (m *Machine) SetClockRate(hz uint64) {
+	if m.leader != nil {
+		m.leader.SetClockRate(hz)
+		return
+	}
+	set := func() {
+		m.clock.rate = hz
+		m.clock.reset(time.Now())
+		m.measure(false)
+	}
+	if !m.running.Load() {
+		set()
+		return
+	}
+	m.do(set)
+}
+
+// Pause pauses a running Machine, or resumes it if it is already paused.
+func (m *Machine) Pause() {
+	if m.leader != nil {
+		m.leader.Pause()
+		return
+	}
+	m.do(func() {
+		m.paused = !m.paused
+		m.clock.reset(time.Now())
+		m.measure(false)
+	})
+}
+
+// Step executes a single instruction if the Machine is paused.
+func (m *Machine) Step() {
+	if m.leader != nil {
+		m.leader.Step()
+		return
+	}
+	m.do(func() {
+		if m.paused {
+			m.tick(1)
+			m.publish()
+		}
+	})
+}
+
+// Run runs the computer until ctx is done, publishing the final state of its screen before returning. Run must only be
+// called once.
+func (m *Machine) Run(ctx context.Context) {
+	m.running.Store(true)
+	defer close(m.done)
+	defer m.publish()
+	// The channels of the follower are nil without one, and receiving from a nil channel never completes
+	var keys chan uint16
+	var control chan func()
+	if m.follower != nil {
+		m.follower.running.Store(true)
+		defer close(m.follower.done)
+		keys, control = m.follower.keys, m.follower.control
+	}
+	now := time.Now()
+	m.clock.reset(now)
+	m.measured = now
+	m.measure(false)
+	for {
+		if m.paused || m.halted != nil {
+			// There is nothing to execute so the machine simply waits for something to happen
+			timer := time.NewTimer(time.Second)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case fn := <-m.control:
+				m.call(fn)
+			case code := <-m.keys:
+				m.key(code)
+			case fn := <-control:
+				m.follower.call(fn)
+			case code := <-keys:
+				m.follower.key(code)
+			case <-timer.C:
+			}
+			timer.Stop()
+		} else if !m.poll(ctx, keys, control) {
+			return
+		} else {
+			m.run()
+		}
+		if time.Since(m.published) >= time.Second/time.Duration(ScreenRefreshRateHz) {
+			m.publish()
+		}
+		if time.Since(m.measured) >= time.Second {
+			m.measure(true)
+		}
+	}
+}
+
+// poll handles everything that is waiting for the machine, or for its follower through keys and control, without
+// blocking. False is returned if ctx is done.
+func (m *Machine) poll(ctx context.Context, keys chan uint16, control chan func()) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case fn := <-m.control:
+			m.call(fn)
+		case code := <-m.keys:
+			m.key(code)
+		case fn := <-control:
+			m.follower.call(fn)
+		case code := <-keys:
+			m.follower.key(code)
+		default:
+			return true
+		}
+	}
+}
+
+// run executes the instructions that are due, or as many as possible until it is time to poll again when the clock rate
+// is unlimited
+func (m *Machine) run() {
+	if m.clock.rate == Unlimited {
+		deadline := time.Now().Add(pollInterval)
+		for m.halted == nil && time.Now().Before(deadline) {
+			m.tick(batch)
+		}
+		return
+	}
+	n := m.clock.due(time.Now())
+	if n == 0 {
+		time.Sleep(min(m.clock.wait(), pollInterval))
+		return
+	}
+	m.tick(n)
+}
+
+// tick executes up to n instructions, stopping early if the computer halts
+func (m *Machine) tick(n uint64) {
+	for range n {
+		if m.halted != nil {
+			return
+		}
+		if err := m.computer.Tick(chip.Inactive); err != nil {
+			m.halted = err
+			m.measure(false)
+			return
+		}
+		m.executed++
+		if f := m.follower; f != nil && f.halted == nil {
+			if err := f.computer.Tick(chip.Inactive); err != nil {
+				f.halted = err
+				m.measure(false)
+			}
+		}
+	}
+}
+
+// call calls a function passed to Do, but not before handling the keyboard input that was queued up before it
+func (m *Machine) call(fn func()) {
+	for {
+		select {
+		case code := <-m.keys:
+			m.key(code)
+		default:
+			fn()
+			return
+		}
+	}
+}
+
+func (m *Machine) key(code uint16) {
+	m.keyboard.Press(code)
+}
+
+// publish copies the screen memory map into the next frame
+func (m *Machine) publish() {
+	m.frames.publish(func(words []uint16) {
+		copy(words, m.screen)
+	})
+	m.published = time.Now()
+	if m.follower != nil {
+		m.follower.publish()
+	}
+}
+
+// measure updates the stats of the machine, including the achieved clock rate if rate is set
+func (m *Machine) measure(rate bool) {
+	stats := *m.stats.Load()
+	if rate {
+		stats.IPS = uint64(float64(m.executed) / time.Since(m.measured).Seconds())
+		m.executed = 0
+		m.measured = time.Now()
+	}
+	stats.Rate = m.clock.rate
+	stats.Paused = m.paused
+	stats.Halted = m.halted != nil
+	stats.Err = m.halted
+	m.stats.Store(&stats)
+	if f := m.follower; f != nil {
+		// The follower executes as many instructions as m does for as long as it has not halted
+		followed := stats
+		followed.Halted = f.halted != nil
+		followed.Err = f.halted
+		if followed.Halted {
+			followed.IPS = 0
+		}
+		f.stats.Store(&followed)
+	}
+}
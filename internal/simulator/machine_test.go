@@ -0,0 +1,144 @@
+package simulator
+
+import (
+	"context"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"testing"
+	"time"
+)
+
+// echo continuously copies the keyboard memory map into the first word of the screen
+func echo(t *testing.T) chip.ROM {
+	t.Helper()
+	rom, err := (&asm.Builder{}).
+		At("LOOP").
+		A("KBD").C("D", "M", "").
+		A("SCREEN").C("M", "D", "").
+		A("LOOP").C("", "0", "JMP").
+		ROM()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rom
+}
+
+// await calls fn until it returns true, failing the test if that does not happen within a few seconds
+func await(t *testing.T, fn func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestMachine_Run exercises every handoff between the goroutine running the machine and its callers, and is meant to be
+// run with the race detector enabled.
+func TestMachine_Run(t *testing.T) {
+	m := NewMachine(echo(t))
+	m.SetClockRate(Unlimited)
+	ctx, cancel := context.WithCancel(context.Background())
+	go m.Run(ctx)
+
+	m.Key(42)
+	await(t, func() bool {
+		return m.Frame().Words[0] == 42
+	})
+
+	m.Pause()
+	await(t, func() bool {
+		return m.Stats().Paused
+	})
+	m.Key(7)
+	var pc uint16
+	m.Do(func(c *chip.Computer) {
+		pc = c.Snapshot().PC
+	})
+	// Stepping through two whole iterations of the loop is certain to echo the key while paused, wherever it was paused
+	for range 12 {
+		m.Step()
+	}
+	await(t, func() bool {
+		return m.Frame().Words[0] == 7
+	})
+	var stepped uint16
+	m.Do(func(c *chip.Computer) {
+		stepped = c.Snapshot().PC
+	})
+	if stepped != pc {
+		t.Errorf("expected to be back at %d after whole iterations but got %d", pc, stepped)
+	}
+
+	m.Pause()
+	m.SetClockRate(1000)
+	if rate := m.Stats().Rate; rate != 1000 {
+		t.Errorf("expected clock rate 1000 but got %d", rate)
+	}
+	m.Key(0)
+	await(t, func() bool {
+		return m.Frame().Words[0] == 0
+	})
+
+	cancel()
+	<-m.Done()
+	if m.Do(func(*chip.Computer) {}) {
+		t.Error("expected Do to fail once the machine has stopped")
+	}
+	if word := m.Computer().Snapshot().RAM[chip.ScreenAddress]; word != 0 {
+		t.Errorf("expected the screen to hold 0 but got %d", word)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	f := newFrames(1)
+	if seq := f.latest().Seq(); seq != 0 {
+		t.Fatalf("expected no frame to be published but got frame %d", seq)
+	}
+	for i := range uint16(3) {
+		f.publish(func(words []uint16) {
+			words[0] = i
+		})
+	}
+	frame := f.latest()
+	if frame.Seq() != 3 || frame.Words[0] != 2 {
+		t.Errorf("expected frame 3 holding 2 but got frame %d holding %d", frame.Seq(), frame.Words[0])
+	}
+	if again := f.latest(); again != frame {
+		t.Error("expected the same frame when nothing new has been published")
+	}
+}
+
+func TestMachine_QueueKeys(t *testing.T) {
+	// Adds every queued key code to the first word of the screen, acknowledging each
+	rom, err := (&asm.Builder{Devices: true}).
+		At("LOOP").
+		A("KBD_STATUS").C("D", "M", "").
+		A("LOOP").C("", "D", "JEQ").
+		A("KBD").C("D", "M", "").
+		C("M", "0", "").
+		A("SCREEN").C("M", "D+M", "").
+		A("LOOP").C("", "0", "JMP").
+		ROM()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewMachine(rom)
+	if err := m.QueueKeys(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Keys pressed and released before the machine even runs are not lost
+	for _, code := range []uint16{'a', 0, 'b', 0} {
+		m.Key(code)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+	await(t, func() bool {
+		return m.Frame().Words[0] == 'a'+'b'
+	})
+	cancel()
+	<-m.Done()
+}
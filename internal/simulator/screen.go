@@ -0,0 +1,197 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// ScreenWidth and ScreenHeight are the dimensions of the Hack screen in pixels
+	ScreenWidth  = 512
+	ScreenHeight = 256
+	// scanlineAlpha is the opacity of the dark lines drawn between rows of pixels by the scanline filter
+	scanlineAlpha = 96
+)
+
+// Palette holds the colors of the pixels on the screen, with each color written as 0xAARRGGBB.
+type Palette struct {
+	// Foreground is the color of pixels that are set to 1, Background the color of pixels set to 0
+	Foreground uint32
+	Background uint32
+}
+
+var (
+	// BlackOnWhite is the classic palette of the Hack platform, as used by the simulators that accompany the book
+	BlackOnWhite = Palette{Foreground: 0xFF000000, Background: 0xFFFFFFFF}
+	// WhiteOnBlack draws white pixels on a black background
+	WhiteOnBlack = Palette{Foreground: 0xFFFFFFFF, Background: 0xFF000000}
+
+	palettes = map[string]Palette{
+		"black-on-white": BlackOnWhite,
+		"white-on-black": WhiteOnBlack,
+	}
+)
+
+// ParsePalette returns the palette with the provided name, which is either black-on-white or white-on-black.
+func ParsePalette(name string) (Palette, error) {
+	p, ok := palettes[name]
+	if !ok {
+		return Palette{}, fmt.Errorf("unknown palette '%s'", name)
+	}
+	return p, nil
+}
+
+// ScreenOptions configures how an SDLScreen is displayed.
+type ScreenOptions struct {
+	// Scale is the initial size of a Hack pixel in pixels of the window, the window may be resized freely afterwards
+	Scale   int
+	Palette Palette
+	// Fullscreen starts the screen in a fullscreen window at the resolution of the desktop
+	Fullscreen bool
+	// Scanlines darkens the bottom row of window pixels of every row of Hack pixels to mimic a CRT monitor. The filter
+	// only has an effect while Hack pixels are scaled up to at least 2x2 window pixels.
+	Scanlines bool
+}
+
+// SDLScreen displays frames of the Hack screen through a streaming texture. The texture is scaled to the largest integer
+// multiple of its size that fits the window and centered within it.
+type SDLScreen struct {
+	window   *sdl.Window
+	renderer *sdl.Renderer
+	texture  *sdl.Texture
+	options  ScreenOptions
+	// scanlines is reused across calls to Draw to hold the rectangles of the scanline filter
+	scanlines []sdl.Rect
+}
+
+// NewSDLScreen opens a window displaying a blank screen. SDL must have been initialized.
+func NewSDLScreen(options ScreenOptions) (*SDLScreen, error) {
+	if options.Scale < 1 {
+		options.Scale = 1
+	}
+	flags := uint32(sdl.WINDOW_SHOWN | sdl.WINDOW_RESIZABLE)
+	if options.Fullscreen {
+		flags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	window, err := sdl.CreateWindow(
+		"Hack",
+		sdl.WINDOWPOS_UNDEFINED,
+		sdl.WINDOWPOS_UNDEFINED,
+		int32(ScreenWidth*options.Scale),
+		int32(ScreenHeight*options.Scale),
+		flags,
+	)
+	if err != nil {
+		return nil, err
+	}
+	renderer, err := sdl.CreateRenderer(window, -1, 0)
+	if err != nil {
+		_ = window.Destroy()
+		return nil, err
+	}
+	texture, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STREAMING, ScreenWidth, ScreenHeight)
+	if err != nil {
+		_ = renderer.Destroy()
+		_ = window.Destroy()
+		return nil, err
+	}
+	s := &SDLScreen{
+		window:   window,
+		renderer: renderer,
+		texture:  texture,
+		options:  options,
+	}
+	if err := s.Draw(&Frame{Words: make([]uint16, chip.ScreenSize)}); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ToggleFullscreen switches between a fullscreen and a windowed screen.
+func (s *SDLScreen) ToggleFullscreen() error {
+	s.options.Fullscreen = !s.options.Fullscreen
+	var flags uint32
+	if s.options.Fullscreen {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	return s.window.SetFullscreen(flags)
+}
+
+// Draw displays frame, scaled to fit the current size of the window.
+func (s *SDLScreen) Draw(frame *Frame) error {
+	pixels, pitch, err := s.texture.Lock(nil)
+	if err != nil {
+		return err
+	}
+	paint(pixels, pitch, frame.Words, s.options.Palette)
+	s.texture.Unlock()
+
+	if err := s.renderer.SetDrawColor(0, 0, 0, 255); err != nil {
+		return err
+	}
+	if err := s.renderer.Clear(); err != nil {
+		return err
+	}
+	w, h, err := s.renderer.GetOutputSize()
+	if err != nil {
+		return err
+	}
+	scale := max(min(w/ScreenWidth, h/ScreenHeight), 1)
+	dst := sdl.Rect{
+		X: (w - ScreenWidth*scale) / 2,
+		Y: (h - ScreenHeight*scale) / 2,
+		W: ScreenWidth * scale,
+		H: ScreenHeight * scale,
+	}
+	if err := s.renderer.Copy(s.texture, nil, &dst); err != nil {
+		return err
+	}
+	if s.options.Scanlines && scale > 1 {
+		if err := s.scanline(dst, scale); err != nil {
+			return err
+		}
+	}
+	s.renderer.Present()
+	return nil
+}
+
+// scanline darkens the bottom row of window pixels of every row of Hack pixels within dst
+func (s *SDLScreen) scanline(dst sdl.Rect, scale int32) error {
+	s.scanlines = s.scanlines[:0]
+	for row := range int32(ScreenHeight) {
+		s.scanlines = append(s.scanlines, sdl.Rect{X: dst.X, Y: dst.Y + row*scale + scale - 1, W: dst.W, H: 1})
+	}
+	if err := s.renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND); err != nil {
+		return err
+	}
+	if err := s.renderer.SetDrawColor(0, 0, 0, scanlineAlpha); err != nil {
+		return err
+	}
+	return s.renderer.FillRects(s.scanlines)
+}
+
+func (s *SDLScreen) Close() {
+	_ = s.texture.Destroy()
+	_ = s.renderer.Destroy()
+	_ = s.window.Destroy()
+}
+
+// paint converts the words of the screen memory map into 32-bit ARGB pixels, stored in native byte order with pitch bytes
+// between the starts of consecutive rows. As the Hack platform specifies, the pixel in row r and column c is the bit
+// c%16 of the word r*32+c/16, counting bits from the least significant.
+func paint(pixels []byte, pitch int, words []uint16, palette Palette) {
+	const wordsPerRow = ScreenWidth / 16
+	for i, word := range words {
+		offset := (i/wordsPerRow)*pitch + (i%wordsPerRow)*16*4
+		for bit := range 16 {
+			color := palette.Background
+			if word&(1<<bit) != 0 {
+				color = palette.Foreground
+			}
+			binary.NativeEndian.PutUint32(pixels[offset+bit*4:], color)
+		}
+	}
+}
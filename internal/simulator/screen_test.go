@@ -0,0 +1,38 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"testing"
+)
+
+func TestPaint(t *testing.T) {
+	const pitch = ScreenWidth * 4
+	words := make([]uint16, chip.ScreenSize)
+	// The leftmost pixel of the first row and the second pixel of the second word of the second row
+	words[0] = 0b0000_0000_0000_0001
+	words[33] = 0b0000_0000_0000_0010
+	pixels := make([]byte, pitch*ScreenHeight)
+	paint(pixels, pitch, words, BlackOnWhite)
+	set := map[[2]int]bool{{0, 0}: true, {1, 17}: true}
+	for row := range ScreenHeight {
+		for col := range ScreenWidth {
+			expected := BlackOnWhite.Background
+			if set[[2]int{row, col}] {
+				expected = BlackOnWhite.Foreground
+			}
+			if actual := binary.NativeEndian.Uint32(pixels[row*pitch+col*4:]); actual != expected {
+				t.Fatalf("expected pixel %d,%d to be %#x but got %#x", row, col, expected, actual)
+			}
+		}
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	if p, err := ParsePalette("black-on-white"); err != nil || p != BlackOnWhite {
+		t.Errorf("expected %v but got %v, %v", BlackOnWhite, p, err)
+	}
+	if _, err := ParsePalette("green"); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
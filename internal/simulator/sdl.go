@@ -1,240 +1,384 @@
 package simulator
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/crookdc/nand2tetris/internal/chip"
+	"github.com/crookdc/nand2tetris/internal/device"
 	"github.com/veandco/go-sdl2/sdl"
-	"log"
+	"time"
 )
 
 var (
-	ScreenMemoryMapBegin            = 16_384
-	ScreenMemoryMapLength           = 8192
-	ScreenRefreshRateHz      uint64 = 33
-	KeyboardMemoryMapAddress uint16 = 24_576
-
-	keymap = map[sdl.Keycode]uint16{
-		sdl.K_SPACE:          32,
-		sdl.K_EXCLAIM:        33,
-		sdl.K_QUOTEDBL:       34,
-		sdl.K_HASH:           35,
-		sdl.K_DOLLAR:         36,
-		sdl.K_PERCENT:        37,
-		sdl.K_AMPERSAND:      38,
-		sdl.K_QUOTE:          39,
-		sdl.K_LEFTPAREN:      40,
-		sdl.K_RIGHTPAREN:     41,
-		sdl.K_ASTERISK:       42,
-		sdl.K_PLUS:           43,
-		sdl.K_COMMA:          44,
-		sdl.K_MINUS:          45,
-		sdl.K_PERIOD:         46,
-		sdl.K_SLASH:          47,
-		sdl.K_0:              48,
-		sdl.K_1:              49,
-		sdl.K_2:              50,
-		sdl.K_3:              51,
-		sdl.K_4:              52,
-		sdl.K_5:              53,
-		sdl.K_6:              54,
-		sdl.K_7:              55,
-		sdl.K_8:              56,
-		sdl.K_9:              57,
-		sdl.K_COLON:          58,
-		sdl.K_SEMICOLON:      59,
-		sdl.K_LESS:           60,
-		sdl.K_EQUALS:         61,
-		sdl.K_GREATER:        62,
-		sdl.K_QUESTION:       63,
-		sdl.K_AT:             64,
-		sdl.K_LEFTBRACKET:    91,
-		sdl.K_RIGHTBRACKET:   93,
-		sdl.K_CARET:          94,
-		sdl.K_UNDERSCORE:     95,
-		sdl.K_BACKQUOTE:      96,
-		sdl.K_a:              97,
-		sdl.K_b:              98,
-		sdl.K_c:              99,
-		sdl.K_d:              100,
-		sdl.K_e:              101,
-		sdl.K_f:              102,
-		sdl.K_g:              103,
-		sdl.K_h:              104,
-		sdl.K_i:              105,
-		sdl.K_j:              106,
-		sdl.K_k:              107,
-		sdl.K_l:              108,
-		sdl.K_m:              109,
-		sdl.K_n:              110,
-		sdl.K_o:              111,
-		sdl.K_p:              112,
-		sdl.K_q:              113,
-		sdl.K_r:              114,
-		sdl.K_s:              115,
-		sdl.K_t:              116,
-		sdl.K_u:              117,
-		sdl.K_v:              118,
-		sdl.K_w:              119,
-		sdl.K_x:              120,
-		sdl.K_y:              121,
-		sdl.K_z:              122,
-		sdl.K_KP_LEFTBRACE:   123,
-		sdl.K_KP_VERTICALBAR: 124,
-		sdl.K_KP_RIGHTBRACE:  125,
-		sdl.K_DELETE:         127,
-		sdl.K_KP_ENTER:       128,
-		sdl.K_BACKSPACE:      129,
-		sdl.K_LEFT:           130,
-		sdl.K_UP:             131,
-		sdl.K_RIGHT:          132,
-		sdl.K_DOWN:           133,
-		sdl.K_ESCAPE:         140,
-	}
+	ScreenRefreshRateHz uint64 = 33
+	// SnapshotKey triggers a call to SDLSimulator.OnSnapshot. It is deliberately a key that has no Hack character code
+	// so that the running program never observes it.
+	SnapshotKey sdl.Keycode = sdl.K_PRINTSCREEN
+	// PauseKey, StepKey, FasterKey and SlowerKey control the clock of the simulator when pressed together with Ctrl.
+	// Pressing PauseKey pauses and resumes execution, StepKey executes a single instruction while paused and FasterKey
+	// and SlowerKey double and halve the clock rate respectively.
+	PauseKey  sdl.Keycode = sdl.K_p
+	StepKey   sdl.Keycode = sdl.K_n
+	FasterKey sdl.Keycode = sdl.K_EQUALS
+	SlowerKey sdl.Keycode = sdl.K_MINUS
+	// FullscreenKey switches between a fullscreen and a windowed screen when pressed together with Ctrl
+	FullscreenKey sdl.Keycode = sdl.K_f
 )
 
+// SDLSimulator displays the screen of a Machine in an SDL window and passes keyboard input on to it. All of its methods
+// must be called from the main OS thread, as required by SDL, while the Machine runs on a goroutine of its own.
+//
+// A second computer can be linked to the first, see Link, in which case each computer is shown in a window of its own
+// and keyboard input goes to the computer whose window has focus.
 type SDLSimulator struct {
-	computer   chip.Computer
-	screen     SDLScreen
-	capitalize bool
-	Running    bool
-	ticks      uint64
+	// views holds the first computer, which the methods of the simulator apply to unless documented otherwise, followed
+	// by the computers linked to it
+	views   []*view
+	options ScreenOptions
+	keymap  *Keymap
+	// queueKeys is set once the keyboards are switched to queued mode
+	queueKeys bool
+	audio     *SDLAudio
+	Running   bool
+	// OnSnapshot is called with a snapshot of the first computer whenever SnapshotKey is pressed, unless it is nil
+	OnSnapshot func(chip.Snapshot)
+	titled     time.Time
+}
+
+// view is a Machine shown in a window of its own
+type view struct {
+	machine  *Machine
+	screen   *SDLScreen
+	window   uint32
+	keyboard keyboard
+	title    string
+	// drawn is the sequence number of the most recently drawn frame, which is drawn again if redraw is set
+	drawn  uint64
+	redraw bool
 }
 
-func NewSDLSimulator(rom chip.ROM) (*SDLSimulator, error) {
+func NewSDLSimulator(rom chip.ROM, options ScreenOptions) (*SDLSimulator, error) {
 	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		return nil, err
 	}
-	window, err := sdl.CreateWindow("Hack", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, 512, 256, sdl.WINDOW_SHOWN)
-	if err != nil {
+	s := &SDLSimulator{options: options, keymap: DefaultKeymap(), Running: true}
+	if err := s.show(NewMachine(rom), "Hack"); err != nil {
+		sdl.Quit()
 		return nil, err
 	}
-	renderer, err := sdl.CreateRenderer(window, 0, 0)
+	sdl.StartTextInput()
+	return s, nil
+}
+
+// show opens a window displaying the screen of machine
+func (s *SDLSimulator) show(machine *Machine, title string) error {
+	screen, err := NewSDLScreen(s.options)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	screen := SDLScreen{
-		window:   window,
-		renderer: renderer,
+	id, err := screen.window.GetID()
+	if err != nil {
+		screen.Close()
+		return err
 	}
-	if err := screen.clear(); err != nil {
-		return nil, err
+	s.views = append(s.views, &view{
+		machine:  machine,
+		screen:   screen,
+		window:   id,
+		keyboard: keyboard{keymap: s.keymap, queued: s.queueKeys},
+		title:    title,
+	})
+	return nil
+}
+
+// Link runs a second computer with the program in rom, connected to the first through a pair of device.UART as described
+// by the package level Link, and shows it in a window of its own. It must be called before Run.
+func (s *SDLSimulator) Link(rom chip.ROM, lockstep bool) error {
+	machine := NewMachine(rom)
+	if err := Link(s.views[0].machine, machine, lockstep); err != nil {
+		return err
+	}
+	if s.queueKeys {
+		if err := machine.QueueKeys(); err != nil {
+			return err
+		}
 	}
-	screen.renderer.Present()
-	return &SDLSimulator{
-		computer:   chip.NewComputer(rom),
-		screen:     screen,
-		capitalize: false,
-		Running:    true,
-	}, nil
+	return s.show(machine, "Hack (linked)")
 }
 
-func (s *SDLSimulator) Close() {
-	s.screen.Close()
-	sdl.Quit()
+// Fill configures the simulated computers to execute instr for every address beyond the end of their programs instead of
+// halting. It must be called before Run.
+func (s *SDLSimulator) Fill(instr [16]chip.Signal) {
+	for _, v := range s.views {
+		v.machine.Computer().Fill(instr)
+	}
 }
 
-func (s *SDLSimulator) Update() {
-	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-		switch e := event.(type) {
-		case *sdl.QuitEvent:
-			s.Running = false
-		case *sdl.KeyboardEvent:
-			if e.State == sdl.PRESSED {
-				s.onKeyPressed(e.Keysym.Sym)
-			} else {
-				s.onKeyReleased(e.Keysym.Sym)
-			}
-		}
+// Trace installs a Tracer in the simulated computer. It must be called before Run and the Tracer is called from the
+// goroutine running the computer.
+func (s *SDLSimulator) Trace(t chip.Tracer) {
+	s.views[0].machine.Computer().Trace(t)
+}
+
+// Snapshot captures the current state of the simulated computer. It must not be called while Run is running.
+func (s *SDLSimulator) Snapshot() chip.Snapshot {
+	return s.views[0].machine.Computer().Snapshot()
+}
+
+// Restore replaces the state of the simulated computer with the state captured in snapshot. It must be called before
+// Run.
+func (s *SDLSimulator) Restore(snapshot chip.Snapshot) error {
+	return s.views[0].machine.Computer().Restore(snapshot)
+}
+
+// Attach maps a device into the memory of the simulated computer, see chip.Bus.Attach. It must be called before Run.
+func (s *SDLSimulator) Attach(name string, start uint16, size int, device chip.Device) error {
+	return s.views[0].machine.Attach(name, start, size, device)
+}
+
+// Play plays the sound of tone while Run is running. The tone must also be attached for the computer to control it.
+func (s *SDLSimulator) Play(tone *device.Tone) error {
+	audio, err := NewSDLAudio(tone)
+	if err != nil {
+		return err
 	}
-	s.computer.Tick(chip.Inactive)
-	if sdl.GetTicks64()-s.ticks > 1000/ScreenRefreshRateHz {
-		if err := s.screen.Draw(s.computer.RAM()); err != nil {
-			log.Fatal(err)
-		}
-		s.ticks = sdl.GetTicks64()
+	if s.audio != nil {
+		s.audio.Close()
 	}
+	s.audio = audio
+	return nil
 }
 
-func (s *SDLSimulator) onKeyPressed(key sdl.Keycode) {
-	switch key {
-	case sdl.K_RSHIFT, sdl.K_LSHIFT:
-		s.capitalize = !s.capitalize
-	default:
+// SetKeymap replaces the DefaultKeymap used to translate keyboard input for every computer.
+func (s *SDLSimulator) SetKeymap(k *Keymap) {
+	s.keymap = k
+	for _, v := range s.views {
+		v.keyboard = keyboard{keymap: k, queued: s.queueKeys}
 	}
-	if mapped, ok := keymap[key]; ok {
-		if s.capitalize && mapped >= keymap[sdl.K_a] && mapped <= keymap[sdl.K_z] {
-			mapped -= 32
+}
+
+// QueueKeys switches the keyboards of the computers to queued mode, see Machine.QueueKeys, including the keyboards of
+// computers linked afterwards. It must be called before Run.
+func (s *SDLSimulator) QueueKeys() error {
+	s.queueKeys = true
+	for _, v := range s.views {
+		if err := v.machine.QueueKeys(); err != nil {
+			return err
 		}
-		s.computer.RAM().Out(chip.Active, chip.WrapUint16(KeyboardMemoryMapAddress).Address(), chip.WrapUint16(mapped))
+		v.keyboard.queued = true
 	}
+	return nil
 }
 
-func (s *SDLSimulator) onKeyReleased(key sdl.Keycode) {
-	switch key {
-	case sdl.K_RSHIFT, sdl.K_LSHIFT, sdl.K_CAPSLOCK:
-		s.capitalize = !s.capitalize
-	}
-	if _, ok := keymap[key]; ok {
-		s.computer.RAM().Out(chip.Active, chip.WrapUint16(KeyboardMemoryMapAddress).Address(), chip.NullWord)
+// SetClockRate sets the rate, in instructions per second, at which the simulated computers are run. The rate can be
+// Unlimited, which is also the default.
+func (s *SDLSimulator) SetClockRate(hz uint64) {
+	for _, m := range s.independent() {
+		m.SetClockRate(hz)
 	}
 }
 
-type SDLScreen struct {
-	window    *sdl.Window
-	renderer  *sdl.Renderer
-	presented uint64
+func (s *SDLSimulator) Close() {
+	if s.audio != nil {
+		s.audio.Close()
+	}
+	for _, v := range s.views {
+		v.screen.Close()
+	}
+	sdl.Quit()
 }
 
-func (s *SDLScreen) clear() error {
-	if err := s.renderer.SetDrawColor(0, 0, 0, 255); err != nil {
-		return err
+// Run runs each simulated computer on a goroutine of its own, unless it runs in lockstep with another, while calling
+// Update until either a window is closed, ctx is done or Update fails. Run returns once the computers have stopped, with
+// the error returned by Update joined with the errors which halted the computers, if any.
+func (s *SDLSimulator) Run(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		errs := []error{err}
+		for _, v := range s.views {
+			<-v.machine.Done()
+			errs = append(errs, v.machine.Stats().Err)
+		}
+		err = errors.Join(errs...)
+	}()
+	for _, m := range s.independent() {
+		go m.Run(ctx)
 	}
-	if err := s.renderer.Clear(); err != nil {
-		return err
+	for s.Running && ctx.Err() == nil {
+		if err := s.Update(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *SDLScreen) Draw(mem chip.Memory) error {
-	if err := s.clear(); err != nil {
-		return err
+// independent returns the machines which are not led by another machine, which are the ones to run and to control
+func (s *SDLSimulator) independent() []*Machine {
+	var machines []*Machine
+	for _, v := range s.views {
+		if v.machine.leader == nil {
+			machines = append(machines, v.machine)
+		}
 	}
-	if err := s.renderer.SetDrawColor(255, 255, 255, 255); err != nil {
-		return err
+	return machines
+}
+
+// view returns the view shown in the window with the provided id, or nil if there is no such window
+func (s *SDLSimulator) view(window uint32) *view {
+	for _, v := range s.views {
+		if v.window == window {
+			return v
+		}
+	}
+	return nil
+}
+
+// Update handles the events that arrive within one screen refresh and then draws the most recent frame of every screen,
+// unless it has already been drawn. An error is returned if a screen cannot be drawn or toggled between fullscreen and a
+// window, or if the sound cannot be played.
+func (s *SDLSimulator) Update() error {
+	timeout := int(1000 / ScreenRefreshRateHz)
+	for event := sdl.WaitEventTimeout(timeout); event != nil; event = sdl.PollEvent() {
+		switch e := event.(type) {
+		case *sdl.QuitEvent:
+			s.Running = false
+		case *sdl.WindowEvent:
+			v := s.view(e.WindowID)
+			if v == nil {
+				break
+			}
+			switch e.Event {
+			case sdl.WINDOWEVENT_SIZE_CHANGED, sdl.WINDOWEVENT_EXPOSED:
+				v.redraw = true
+			case sdl.WINDOWEVENT_CLOSE:
+				// SDL only quits by itself once the last window is closed
+				s.Running = false
+			}
+		case *sdl.KeyboardEvent:
+			v := s.view(e.WindowID)
+			if v == nil {
+				break
+			}
+			if e.State == sdl.PRESSED {
+				if err := s.onKeyPressed(v, e.Keysym); err != nil {
+					return err
+				}
+			} else if code, ok := v.keyboard.release(e.Keysym); ok {
+				v.machine.Key(code)
+			}
+		case *sdl.TextInputEvent:
+			if v := s.view(e.WindowID); v != nil {
+				if code, ok := v.keyboard.text(e.GetText()); ok {
+					v.machine.Key(code)
+				}
+			}
+		}
 	}
-	points := make([]sdl.Point, 0, ScreenMemoryMapLength)
-	for i := range ScreenMemoryMapLength {
-		val := mem.Out(chip.Inactive, chip.WrapUint16(uint16(ScreenMemoryMapBegin+i)).Address(), chip.NullWord)
-		points = append(points, s.points(i, val)...)
+	for _, v := range s.views {
+		if frame := v.machine.Frame(); frame.Seq() != v.drawn || v.redraw {
+			if err := v.screen.Draw(frame); err != nil {
+				return err
+			}
+			v.drawn = frame.Seq()
+			v.redraw = false
+		}
 	}
-	if len(points) == 0 {
-		// If there are no points to render then the renderer will return an error in DrawPoints. Even if that was not
-		// the case then it would just be wasteful to call the renderer if there is nothing to render.
-		return nil
+	if s.audio != nil {
+		if err := s.audio.Update(); err != nil {
+			return err
+		}
 	}
-	if err := s.renderer.DrawPoints(points); err != nil {
-		return err
+	if time.Since(s.titled) >= time.Second {
+		s.updateTitle()
 	}
-	s.renderer.Present()
 	return nil
 }
 
-func (s *SDLScreen) points(position int, val chip.ReadonlyWord) []sdl.Point {
-	points := make([]sdl.Point, 0, 16)
-	row := position / 32
-	for i := range 16 {
-		px := val.Get(i)
-		col := ((position * 16) % 512) + i
-		if px == chip.Inactive {
-			continue
+// updateTitle shows the achieved and the target clock rate of each computer in the title of its window
+func (s *SDLSimulator) updateTitle() {
+	for _, v := range s.views {
+		stats := v.machine.Stats()
+		target := "unlimited"
+		if stats.Rate != Unlimited {
+			target = fmt.Sprintf("%d Hz", stats.Rate)
+		}
+		title := fmt.Sprintf("%s - %d instructions/s (target %s)", v.title, stats.IPS, target)
+		switch {
+		case stats.Halted:
+			title += " - halted"
+		case stats.Paused:
+			title += " - paused"
+		}
+		v.screen.window.SetTitle(title)
+	}
+	s.titled = time.Now()
+}
+
+// onHotkey handles the keys which control the simulator rather than being passed on to a computer. Apart from
+// FullscreenKey, which applies to the window of v, hotkeys apply to every computer. False is returned if key is not a
+// hotkey and an error is returned if the window of v cannot be toggled to or from fullscreen.
+func (s *SDLSimulator) onHotkey(v *view, key sdl.Keysym) (bool, error) {
+	if key.Sym == SnapshotKey {
+		if s.OnSnapshot != nil {
+			var snapshot chip.Snapshot
+			if s.views[0].machine.Do(func(c *chip.Computer) {
+				snapshot = c.Snapshot()
+			}) {
+				s.OnSnapshot(snapshot)
+			}
+		}
+		return true, nil
+	}
+	if key.Mod&sdl.KMOD_CTRL == 0 {
+		return false, nil
+	}
+	switch key.Sym {
+	case PauseKey, StepKey, FasterKey, SlowerKey:
+		for _, m := range s.independent() {
+			control(m, key.Sym)
+		}
+	case FullscreenKey:
+		if err := v.screen.ToggleFullscreen(); err != nil {
+			return true, err
+		}
+		v.redraw = true
+	default:
+		return false, nil
+	}
+	s.updateTitle()
+	return true, nil
+}
+
+// control applies the hotkey sym, which is one of the keys that control the clock, to m
+func control(m *Machine, sym sdl.Keycode) {
+	switch sym {
+	case PauseKey:
+		m.Pause()
+	case StepKey:
+		m.Step()
+	case FasterKey:
+		if rate := m.Stats().Rate; rate != Unlimited {
+			m.SetClockRate(rate * 2)
+		}
+	case SlowerKey:
+		stats := m.Stats()
+		rate := stats.Rate
+		if rate == Unlimited {
+			// Slowing down from an unlimited rate starts out from the rate that is currently achieved
+			rate = stats.IPS
 		}
-		points = append(points, sdl.Point{
-			X: int32(col),
-			Y: int32(row),
-		})
+		m.SetClockRate(max(rate/2, 1))
 	}
-	return points
 }
 
-func (s *SDLScreen) Close() {
-	_ = s.window.Destroy()
+func (s *SDLSimulator) onKeyPressed(v *view, key sdl.Keysym) error {
+	if ok, err := s.onHotkey(v, key); ok || err != nil {
+		return err
+	}
+	if code, ok := v.keyboard.press(key); ok {
+		v.machine.Key(code)
+	}
+	return nil
 }
@@ -0,0 +1,140 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"github.com/veandco/go-sdl2/sdl"
+	"io"
+	"math"
+	"time"
+)
+
+// audioLatency is the amount of sound kept queued up for the audio device, which must outlast the time between two calls
+// to SDLAudio.Update
+const audioLatency = 100 * time.Millisecond
+
+// samplesDue returns the number of samples that make up d
+func samplesDue(d time.Duration) int {
+	return int(d.Milliseconds() * device.SampleRate / 1000)
+}
+
+// SDLAudio plays the sound of a device.Tone through the default audio device. SDL must have been initialized.
+type SDLAudio struct {
+	device  sdl.AudioDeviceID
+	tone    *device.Tone
+	samples []int16
+	bytes   []byte
+}
+
+func NewSDLAudio(tone *device.Tone) (*SDLAudio, error) {
+	spec := sdl.AudioSpec{Freq: device.SampleRate, Format: sdl.AUDIO_S16SYS, Channels: 1, Samples: 512}
+	id, err := sdl.OpenAudioDevice("", false, &spec, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	sdl.PauseAudioDevice(id, false)
+	return &SDLAudio{device: id, tone: tone}, nil
+}
+
+// Update tops up the queue of the audio device with the sound rendered by the tone.
+func (a *SDLAudio) Update() error {
+	queued := int(sdl.GetQueuedAudioSize(a.device)) / 2
+	n := samplesDue(audioLatency) - queued
+	if n <= 0 {
+		return nil
+	}
+	a.samples = a.samples[:0]
+	a.samples = append(a.samples, make([]int16, n)...)
+	a.tone.Render(a.samples)
+	a.bytes = a.bytes[:0]
+	for _, s := range a.samples {
+		a.bytes = binary.NativeEndian.AppendUint16(a.bytes, uint16(s))
+	}
+	return sdl.QueueAudio(a.device, a.bytes)
+}
+
+func (a *SDLAudio) Close() {
+	sdl.CloseAudioDevice(a.device)
+}
+
+// wavHeaderSize is the size of the header written by WAVWriter, which is followed by the samples
+const wavHeaderSize = 44
+
+// WAVWriter writes mono signed 16-bit sound at device.SampleRate to a WAV file. The sizes in the header are only known
+// once all samples have been written, which is why the writer needs to seek back to the header on Close.
+type WAVWriter struct {
+	w       io.WriteSeeker
+	samples int
+	buf     []byte
+}
+
+// NewWAVWriter writes the header of an empty WAV file to w, which samples are then appended to.
+func NewWAVWriter(w io.WriteSeeker) (*WAVWriter, error) {
+	wav := &WAVWriter{w: w}
+	if err := wav.header(); err != nil {
+		return nil, err
+	}
+	return wav, nil
+}
+
+func (w *WAVWriter) Write(samples []int16) error {
+	if (w.samples+len(samples))*2 > math.MaxUint32-wavHeaderSize {
+		return errors.New("sound exceeds the maximum size of a WAV file")
+	}
+	w.buf = w.buf[:0]
+	for _, s := range samples {
+		w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(s))
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		return err
+	}
+	w.samples += len(samples)
+	return nil
+}
+
+// Close completes the header with the number of samples written. It does not close the underlying writer.
+func (w *WAVWriter) Close() error {
+	if _, err := w.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := w.header(); err != nil {
+		return err
+	}
+	_, err := w.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// header writes the header of a WAV file holding the samples written so far
+func (w *WAVWriter) header() error {
+	size := uint32(w.samples * 2)
+	return binary.Write(w.w, binary.LittleEndian, struct {
+		RIFF          [4]byte
+		Size          uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          wavHeaderSize - 8 + size,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1,
+		Channels:      1,
+		SampleRate:    device.SampleRate,
+		ByteRate:      device.SampleRate * 2,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      size,
+	})
+}
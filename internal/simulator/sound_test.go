@@ -0,0 +1,58 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/crookdc/nand2tetris/internal/device"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWAVWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sound.wav")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	wav, err := NewWAVWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, samples := range [][]int16{{1, -1}, {256}} {
+		if err := wav.Write(samples); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := wav.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data) != wavHeaderSize+6 {
+		t.Fatalf("expected %d bytes but got %d", wavHeaderSize+6, len(data))
+	}
+	var assertions = []struct {
+		name   string
+		offset int
+		want   []byte
+	}{
+		{name: "riff", offset: 0, want: []byte("RIFF")},
+		{name: "riff size", offset: 4, want: binary.LittleEndian.AppendUint32(nil, wavHeaderSize-8+6)},
+		{name: "wave", offset: 8, want: []byte("WAVEfmt ")},
+		{name: "sample rate", offset: 24, want: binary.LittleEndian.AppendUint32(nil, device.SampleRate)},
+		{name: "data size", offset: 36, want: append([]byte("data"), 6, 0, 0, 0)},
+		{name: "samples", offset: 44, want: []byte{1, 0, 0xFF, 0xFF, 0, 1}},
+	}
+	for _, assert := range assertions {
+		t.Run(assert.name, func(t *testing.T) {
+			actual := data[assert.offset : assert.offset+len(assert.want)]
+			if !bytes.Equal(actual, assert.want) {
+				t.Errorf("expected %v but got %v", assert.want, actual)
+			}
+		})
+	}
+}
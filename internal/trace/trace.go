@@ -0,0 +1,193 @@
+// Package trace records the cycles executed by a chip.Computer to JSON Lines or to the Chrome trace event format, the
+// latter of which can be opened in chrome://tracing or https://ui.perfetto.dev.
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"io"
+)
+
+// Range is a range of ROM addresses from First up to and including Last, which lets a range reach the largest address.
+type Range struct {
+	First uint16
+	Last  uint16
+}
+
+func (r Range) contains(pc uint16) bool {
+	return pc >= r.First && pc <= r.Last
+}
+
+// Filter only passes on the cycles that execute an instruction within any of its ranges to the wrapped Tracer. A Filter
+// without any ranges passes on every cycle.
+type Filter struct {
+	Tracer chip.Tracer
+	Ranges []Range
+}
+
+// Routine adds the range of the named routine to the filter, see asm.Routines.Named. False is returned if no such
+// routine exists.
+func (f *Filter) Routine(routines asm.Routines, name string) bool {
+	r, ok := routines.Named(name)
+	if !ok {
+		return false
+	}
+	f.Ranges = append(f.Ranges, Range{First: r.Start, Last: r.End - 1})
+	return true
+}
+
+func (f *Filter) Trace(c chip.Cycle) error {
+	if len(f.Ranges) == 0 {
+		return f.Tracer.Trace(c)
+	}
+	for _, r := range f.Ranges {
+		if r.contains(c.PC) {
+			return f.Tracer.Trace(c)
+		}
+	}
+	return nil
+}
+
+// Write describes a single write to RAM.
+type Write struct {
+	Address uint16 `json:"address"`
+	Value   uint16 `json:"value"`
+}
+
+// Record is the serialized form of a chip.Cycle.
+type Record struct {
+	Cycle       uint64 `json:"cycle"`
+	PC          uint16 `json:"pc"`
+	Label       string `json:"label,omitempty"`
+	Instruction uint16 `json:"instruction"`
+	A           uint16 `json:"a"`
+	D           uint16 `json:"d"`
+	M           uint16 `json:"m"`
+	Write       *Write `json:"write,omitempty"`
+	Jump        bool   `json:"jump"`
+}
+
+func record(c chip.Cycle, routines asm.Routines) Record {
+	r := Record{
+		Cycle:       c.Number,
+		PC:          c.PC,
+		Instruction: c.Instruction,
+		A:           c.A,
+		D:           c.D,
+		M:           c.M,
+		Jump:        c.Jump,
+	}
+	if routine, ok := routines.Find(c.PC); ok {
+		r.Label = routine.Name
+	}
+	if c.Write {
+		r.Write = &Write{
+			Address: c.Address,
+			Value:   c.Value,
+		}
+	}
+	return r
+}
+
+// JSONLines writes every cycle as a JSON object on a line of its own. Cycles are buffered, which means that Close must be
+// called once tracing is done.
+type JSONLines struct {
+	w        *bufio.Writer
+	enc      *json.Encoder
+	routines asm.Routines
+}
+
+// NewJSONLines creates a Tracer which writes to w. Cycles are labelled with the routine they belong to, if any.
+func NewJSONLines(w io.Writer, routines asm.Routines) *JSONLines {
+	buf := bufio.NewWriter(w)
+	return &JSONLines{
+		w:        buf,
+		enc:      json.NewEncoder(buf),
+		routines: routines,
+	}
+}
+
+func (j *JSONLines) Trace(c chip.Cycle) error {
+	return j.enc.Encode(record(c, j.routines))
+}
+
+// Close flushes all buffered cycles to the underlying writer.
+func (j *JSONLines) Close() error {
+	return j.w.Flush()
+}
+
+// event is a complete event ("ph": "X") in the Chrome trace event format
+type event struct {
+	Name      string `json:"name"`
+	Category  string `json:"cat"`
+	Phase     string `json:"ph"`
+	Timestamp uint64 `json:"ts"`
+	Duration  uint64 `json:"dur"`
+	Process   int    `json:"pid"`
+	Thread    int    `json:"tid"`
+	Args      Record `json:"args"`
+}
+
+// Chrome writes every cycle as a complete event in the Chrome trace event format, with one cycle taking one microsecond.
+// Events are named after the routine they belong to so that time spent in each routine stands out when the trace is
+// viewed. Cycles are buffered and the trace is only valid once Close has been called.
+type Chrome struct {
+	w        *bufio.Writer
+	routines asm.Routines
+	events   int
+}
+
+// NewChrome creates a Tracer which writes to w. Cycles are labelled with the routine they belong to, if any.
+func NewChrome(w io.Writer, routines asm.Routines) *Chrome {
+	return &Chrome{
+		w:        bufio.NewWriter(w),
+		routines: routines,
+	}
+}
+
+func (c *Chrome) Trace(cycle chip.Cycle) error {
+	prefix := ",\n"
+	if c.events == 0 {
+		prefix = "{\"traceEvents\":[\n"
+	}
+	args := record(cycle, c.routines)
+	name := args.Label
+	if name == "" {
+		name = "-"
+	}
+	bin, err := json.Marshal(event{
+		Name:      name,
+		Category:  "cycle",
+		Phase:     "X",
+		Timestamp: cycle.Number,
+		Duration:  1,
+		Process:   1,
+		Thread:    1,
+		Args:      args,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.w.WriteString(prefix); err != nil {
+		return err
+	}
+	if _, err := c.w.Write(bin); err != nil {
+		return err
+	}
+	c.events++
+	return nil
+}
+
+// Close terminates the trace and flushes it to the underlying writer.
+func (c *Chrome) Close() error {
+	suffix := "\n]}\n"
+	if c.events == 0 {
+		suffix = "{\"traceEvents\":[]}\n"
+	}
+	if _, err := c.w.WriteString(suffix); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
@@ -0,0 +1,149 @@
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/crookdc/nand2tetris/asm"
+	"github.com/crookdc/nand2tetris/internal/chip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// program counts down from 2 to 0 in RAM[0] before spinning in an infinite loop
+const program = `
+@2
+D=A
+(LOOP)
+@R0
+M=D
+D=D-1;JGE
+(END)
+@END
+0;JMP
+`
+
+func routines(t *testing.T) asm.Routines {
+	t.Helper()
+	parsed, err := asm.Parse(strings.NewReader(program))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return asm.NewRoutines(asm.Labels(parsed))
+}
+
+func run(t *testing.T, tracer chip.Tracer, ticks int) {
+	t.Helper()
+	bin, err := asm.Assemble(strings.NewReader(program))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := chip.NewComputer(chip.NewROM(bin))
+	c.Trace(tracer)
+	for range ticks {
+		if err := c.Tick(chip.Inactive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	tracer := NewJSONLines(&out, routines(t))
+	run(t, tracer, 6)
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Record{
+		{Cycle: 0, PC: 0, Instruction: 0b0000_0000_0000_0010, A: 2},
+		{Cycle: 1, PC: 1, Instruction: 0b1110_1100_0001_0000, A: 2, D: 2},
+		{Cycle: 2, PC: 2, Label: "LOOP", Instruction: 0b0000_0000_0000_0000, D: 2},
+		{Cycle: 3, PC: 3, Label: "LOOP", Instruction: 0b1110_0011_0000_1000, D: 2, Write: &Write{Address: 0, Value: 2}},
+		{Cycle: 4, PC: 4, Label: "LOOP", Instruction: 0b1110_0011_1001_0011, D: 1, M: 2, Jump: true},
+		{Cycle: 5, PC: 0, Instruction: 0b0000_0000_0000_0010, A: 2, D: 1, M: 2},
+	}
+	s := bufio.NewScanner(&out)
+	for i := 0; s.Scan(); i++ {
+		var r Record
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(expected[i], r) {
+			t.Errorf("expected %+v but got %+v", expected[i], r)
+		}
+	}
+}
+
+func TestChrome(t *testing.T) {
+	var out bytes.Buffer
+	tracer := NewChrome(&out, nil)
+	run(t, tracer, 3)
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var trace struct {
+		TraceEvents []event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &trace); err != nil {
+		t.Fatalf("expected valid JSON but got %v: %s", err, out.String())
+	}
+	if len(trace.TraceEvents) != 3 {
+		t.Fatalf("expected 3 events but got %d", len(trace.TraceEvents))
+	}
+	for i, e := range trace.TraceEvents {
+		if e.Phase != "X" || e.Timestamp != uint64(i) || e.Duration != 1 || e.Args.PC != uint16(i) {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+	t.Run("empty trace is valid", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := NewChrome(&out, nil).Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !json.Valid(out.Bytes()) {
+			t.Errorf("expected valid JSON but got %s", out.String())
+		}
+	})
+}
+
+func TestFilter(t *testing.T) {
+	var pcs []uint16
+	collect := chip.TracerFunc(func(c chip.Cycle) error {
+		pcs = append(pcs, c.PC)
+		return nil
+	})
+	t.Run("by range", func(t *testing.T) {
+		pcs = nil
+		run(t, &Filter{Tracer: collect, Ranges: []Range{{First: 0, Last: 1}}}, 8)
+		if !reflect.DeepEqual(pcs, []uint16{0, 1, 0, 1}) {
+			t.Errorf("unexpected trace %v", pcs)
+		}
+	})
+	t.Run("by range up to the largest address", func(t *testing.T) {
+		pcs = nil
+		filter := &Filter{Tracer: collect, Ranges: []Range{{First: 65534, Last: 65535}}}
+		for _, pc := range []uint16{0, 65533, 65534, 65535} {
+			if err := filter.Trace(chip.Cycle{PC: pc}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if !reflect.DeepEqual(pcs, []uint16{65534, 65535}) {
+			t.Errorf("unexpected trace %v", pcs)
+		}
+	})
+	t.Run("by routine", func(t *testing.T) {
+		pcs = nil
+		filter := &Filter{Tracer: collect}
+		if !filter.Routine(routines(t), "LOOP") {
+			t.Fatal("expected routine LOOP to exist")
+		}
+		if filter.Routine(nil, "MISSING") {
+			t.Error("expected routine MISSING not to exist")
+		}
+		run(t, filter, 10)
+		if !reflect.DeepEqual(pcs, []uint16{2, 3, 4, 2, 3, 4}) {
+			t.Errorf("unexpected trace %v", pcs)
+		}
+	})
+}